@@ -0,0 +1,39 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IsLegacyNamespace reports whether the namespace belongs to the organization
+// without having to be adopted: it is controlled by the organization, or it
+// was created for the organization by a previous version of the operator,
+// which left it without controller but with the organization and managed-by
+// labels. Namespaces released by an organization with the Orphan deletion
+// policy keep the labels but are not legacy namespaces, they have to be
+// adopted.
+func IsLegacyNamespace(namespace *corev1.Namespace, organization *Organization) bool {
+	if owner := metav1.GetControllerOf(namespace); owner != nil {
+		return organization.UID != "" && owner.UID == organization.UID
+	}
+	if _, released := namespace.Annotations[ReleasedAnnotation]; released {
+		return false
+	}
+	return namespace.Labels[OrganizationLabel] == organization.Name && namespace.Labels[ManagedByLabel] == ManagedByValue
+}
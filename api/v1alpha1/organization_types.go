@@ -17,18 +17,471 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // OrganizationSpec defines the desired state of Organization
 type OrganizationSpec struct {
-	// Add any additional fields if needed
+	// DisplayName is the human readable name of the organization.
+	// +optional
+	// +kubebuilder:validation:MaxLength=256
+	DisplayName string `json:"displayName,omitempty"`
+
+	// Description is a free text description of the organization.
+	// +optional
+	// +kubebuilder:validation:MaxLength=2048
+	Description string `json:"description,omitempty"`
+
+	// Contacts lists the people responsible for the organization.
+	// +optional
+	// +kubebuilder:validation:MaxItems=32
+	Contacts []OrganizationContact `json:"contacts,omitempty"`
+
+	// ExternalIDs lists the identifiers of the organization in external systems, such as CRM or billing.
+	// +optional
+	// +listType=map
+	// +listMapKey=system
+	// +kubebuilder:validation:MaxItems=16
+	ExternalIDs []ExternalID `json:"externalIDs,omitempty"`
+
+	// NamespaceMetadata holds labels and annotations propagated to the organization namespace.
+	// +optional
+	NamespaceMetadata *NamespaceMetadata `json:"namespaceMetadata,omitempty"`
+
+	// DeletionProtection prevents the organization from being deleted. A
+	// protected organization can only be deleted once the protection is
+	// lifted, or when it carries the confirm deletion annotation set to the
+	// organization name.
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// Suspended freezes the organization, e.g. for a billing hold or a
+	// security incident. Its Deployments, StatefulSets, ReplicaSets without
+	// Deployment and ReplicationControllers are scaled to zero, its CronJobs
+	// and running Jobs are suspended, its DaemonSets are kept off every node
+	// and its pods without controller are deleted. Creates and updates of
+	// workloads in its namespace are denied, except for platform identities.
+	// Unsuspending restores the original replicas, suspend fields and node
+	// selectors; deleted pods are not restored.
+	// +optional
+	Suspended bool `json:"suspended,omitempty"`
+
+	// DeletionPolicy defines what happens to the organization namespace when
+	// the organization is deleted.
+	// +optional
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// ClassName references the OrganizationClass providing the defaults of the
+	// organization. Fields set on the organization override the class.
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	ClassName string `json:"className,omitempty"`
+
+	// AdoptNamespace allows the organization to adopt its namespace when the
+	// namespace already exists without belonging to the organization, e.g.
+	// when it was created by hand or retained by a deleted organization.
+	// Without it such namespaces are refused.
+	// +optional
+	AdoptNamespace bool `json:"adoptNamespace,omitempty"`
+
+	// Members are granted access to the organization namespace according to
+	// their role, and read access to the organization itself.
+	// +optional
+	// +kubebuilder:validation:MaxItems=256
+	Members []OrganizationMember `json:"members,omitempty"`
+
+	// AutomationAccounts are ServiceAccounts created in the organization
+	// namespace for automation, bound to their role and issued a kubeconfig.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=32
+	AutomationAccounts []AutomationAccount `json:"automationAccounts,omitempty"`
+
+	// Quota is the ResourceQuota enforced in the organization namespace.
+	// The ResourceQuota is removed when unset.
+	// +optional
+	Quota *corev1.ResourceQuotaSpec `json:"quota,omitempty"`
+
+	// Limits are the LimitRange items enforced in the organization namespace.
+	// The LimitRange is removed when empty.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Limits []corev1.LimitRangeItem `json:"limits,omitempty"`
+
+	// MaxNamespaces caps the number of namespaces labelled with the
+	// organization, including the organization namespace. Exceeding it is
+	// reported in the QuotaReady condition.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxNamespaces *int32 `json:"maxNamespaces,omitempty"`
+
+	// NetworkIsolation overrides the operator default for isolating the
+	// organization namespace with NetworkPolicies.
+	// +optional
+	NetworkIsolation NetworkIsolation `json:"networkIsolation,omitempty"`
+
+	// AllowedPeers lists the organizations whose namespaces may reach the
+	// organization namespace while it is isolated.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:items:MaxLength=63
+	AllowedPeers []string `json:"allowedPeers,omitempty"`
+
+	// PodSecurity sets the Pod Security Admission levels of the organization
+	// namespace. Unset fields default to the operator configuration.
+	// +optional
+	PodSecurity *PodSecurity `json:"podSecurity,omitempty"`
 }
 
+// PodSecurityLevel is a Pod Security Standards level.
+// +kubebuilder:validation:Enum=privileged;baseline;restricted
+type PodSecurityLevel string
+
+const (
+	// PodSecurityLevelPrivileged is the unrestricted level.
+	PodSecurityLevelPrivileged PodSecurityLevel = "privileged"
+	// PodSecurityLevelBaseline prevents known privilege escalations.
+	PodSecurityLevelBaseline PodSecurityLevel = "baseline"
+	// PodSecurityLevelRestricted follows the pod hardening best practices.
+	PodSecurityLevelRestricted PodSecurityLevel = "restricted"
+)
+
+// PodSecurity holds the Pod Security Admission levels and versions of a
+// namespace, set as pod-security.kubernetes.io labels.
+type PodSecurity struct {
+	// Enforce is the level pods are rejected for violating.
+	// +optional
+	Enforce PodSecurityLevel `json:"enforce,omitempty"`
+
+	// EnforceVersion is the Kubernetes version of the enforced level, e.g. latest or v1.30.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(latest|v[0-9]+\.[0-9]+)$`
+	EnforceVersion string `json:"enforceVersion,omitempty"`
+
+	// Audit is the level violations are recorded in the audit log for.
+	// +optional
+	Audit PodSecurityLevel `json:"audit,omitempty"`
+
+	// AuditVersion is the Kubernetes version of the audited level.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(latest|v[0-9]+\.[0-9]+)$`
+	AuditVersion string `json:"auditVersion,omitempty"`
+
+	// Warn is the level users are warned about violating.
+	// +optional
+	Warn PodSecurityLevel `json:"warn,omitempty"`
+
+	// WarnVersion is the Kubernetes version of the warned level.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(latest|v[0-9]+\.[0-9]+)$`
+	WarnVersion string `json:"warnVersion,omitempty"`
+}
+
+// NetworkIsolation defines whether the organization namespace is isolated
+// with NetworkPolicies.
+// +kubebuilder:validation:Enum=Enabled;Disabled
+type NetworkIsolation string
+
+const (
+	// NetworkIsolationEnabled denies ingress traffic to the organization
+	// namespace, except from the organization itself, from the platform
+	// namespaces and from the allowed peers.
+	NetworkIsolationEnabled NetworkIsolation = "Enabled"
+	// NetworkIsolationDisabled leaves the organization namespace open.
+	NetworkIsolationDisabled NetworkIsolation = "Disabled"
+)
+
+// MemberKind is the kind of subject of an organization member.
+// +kubebuilder:validation:Enum=User;Group;ServiceAccount
+type MemberKind string
+
+const (
+	// MemberKindUser is a user authenticated by the cluster.
+	MemberKindUser MemberKind = "User"
+	// MemberKindGroup is a group of users authenticated by the cluster.
+	MemberKindGroup MemberKind = "Group"
+	// MemberKindServiceAccount is a Kubernetes service account.
+	MemberKindServiceAccount MemberKind = "ServiceAccount"
+)
+
+// OrganizationMember is a subject granted access to the organization namespace.
+type OrganizationMember struct {
+	// Kind is the kind of the subject.
+	Kind MemberKind `json:"kind"`
+
+	// Name is the name of the user, group or service account.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	Name string `json:"name"`
+
+	// Namespace is the namespace of a service account. Defaults to the
+	// organization namespace.
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string `json:"namespace,omitempty"`
+
+	// Role is the role of the member in the organization, e.g. admin, editor
+	// or viewer. The ClusterRole bound for each role is configured on the
+	// operator.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Role string `json:"role"`
+}
+
+// AutomationAccount is a ServiceAccount issued a kubeconfig for automation.
+type AutomationAccount struct {
+	// Name is the name of the account. The ServiceAccount and its RoleBinding
+	// are named automation-<name>, the kubeconfig Secret
+	// automation-<name>-kubeconfig.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+
+	// Role is the role of the account in the organization, bound like the
+	// role of a member.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Role string `json:"role"`
+
+	// ExpirationSeconds is the requested lifetime of the issued tokens.
+	// Defaults to the operator configuration. Tokens are rotated once 80% of
+	// their lifetime has elapsed.
+	// +optional
+	// +kubebuilder:validation:Minimum=600
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// DeletionPolicy defines what happens to the organization namespace when the
+// organization is deleted.
+// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the namespace together with the organization.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain leaves the namespace in place, stripped of the owner
+	// reference and of the labels and annotations managed by the operator.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan leaves the namespace in place with its labels and
+	// annotations, but releases the ownership and marks the namespace as
+	// released, so that another Organization can adopt it with
+	// spec.adoptNamespace.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// OrganizationPhase summarizes the lifecycle of an organization.
+// +kubebuilder:validation:Enum=Pending;Active;Terminating;Failed
+type OrganizationPhase string
+
+const (
+	// OrganizationPhasePending is set until the organization namespace is
+	// reconciled for the first time, e.g. while its class is missing.
+	OrganizationPhasePending OrganizationPhase = "Pending"
+	// OrganizationPhaseActive is set once the organization namespace is reconciled.
+	OrganizationPhaseActive OrganizationPhase = "Active"
+	// OrganizationPhaseTerminating is set while the organization is being deleted.
+	OrganizationPhaseTerminating OrganizationPhase = "Terminating"
+	// OrganizationPhaseFailed is set when the organization or the objects
+	// managed for it fail to reconcile.
+	OrganizationPhaseFailed OrganizationPhase = "Failed"
+)
+
+// NamespaceMetadata holds metadata propagated to the organization namespace.
+// Keys removed from here are removed from the namespace as well.
+type NamespaceMetadata struct {
+	// Labels are merged onto the labels of the organization namespace.
+	// +optional
+	// +kubebuilder:validation:MaxProperties=64
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are merged onto the annotations of the organization namespace.
+	// +optional
+	// +kubebuilder:validation:MaxProperties=64
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// OrganizationContact describes a person responsible for the organization.
+type OrganizationContact struct {
+	// Name is the full name of the contact.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	Name string `json:"name"`
+
+	// Email is the email address of the contact.
+	// +kubebuilder:validation:Format=email
+	// +kubebuilder:validation:MaxLength=254
+	Email string `json:"email"`
+
+	// Role describes the responsibility of the contact, e.g. technical, billing or security.
+	// +optional
+	// +kubebuilder:validation:MaxLength=64
+	Role string `json:"role,omitempty"`
+}
+
+// ExternalID is the identifier of the organization in an external system.
+type ExternalID struct {
+	// System is the name of the external system, e.g. crm or billing.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	System string `json:"system"`
+
+	// ID is the identifier of the organization in the external system.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	ID string `json:"id"`
+}
+
+const (
+	// ReadyCondition reports whether every object managed for the organization is reconciled.
+	ReadyCondition = "Ready"
+	// NamespaceReadyCondition reports whether the organization namespace is reconciled.
+	NamespaceReadyCondition = "NamespaceReady"
+	// TerminatingCondition reports whether the organization is being deleted.
+	TerminatingCondition = "Terminating"
+	// DeletionStalledCondition reports whether the organization namespace was not deleted within the deletion timeout.
+	// The operator then stops polling the namespace and only reacts to its changes.
+	DeletionStalledCondition = "DeletionStalled"
+	// MembersReadyCondition reports whether the RoleBindings of the organization members, and their
+	// read access to the organization, are reconciled.
+	MembersReadyCondition = "MembersReady"
+	// QuotaReadyCondition reports whether the ResourceQuota and LimitRange of the organization are reconciled.
+	QuotaReadyCondition = "QuotaReady"
+	// NetworkIsolationReadyCondition reports whether the NetworkPolicies isolating the organization namespace are reconciled.
+	NetworkIsolationReadyCondition = "NetworkIsolationReady"
+	// PodSecurityReadyCondition reports whether the Pod Security Admission labels of the organization namespace are
+	// reconciled. It is False while a stricter enforce level is held back because existing pods violate it.
+	PodSecurityReadyCondition = "PodSecurityReady"
+	// AutomationAccountsReadyCondition reports whether the ServiceAccounts, RoleBindings and kubeconfig
+	// Secrets of the automation accounts are reconciled.
+	AutomationAccountsReadyCondition = "AutomationAccountsReady"
+	// SuspendedCondition reports whether the organization is suspended and its workloads are stopped.
+	SuspendedCondition = "Suspended"
+	// ClassReadyCondition reports whether the OrganizationClass referenced by the organization exists.
+	ClassReadyCondition = "ClassReady"
+)
+
 // OrganizationStatus defines the observed state of Organization
 type OrganizationStatus struct {
+	// Phase summarizes the lifecycle of the organization.
+	// +optional
+	Phase OrganizationPhase `json:"phase,omitempty"`
+
 	// Namespace is the namespace containing the resources for this organization.
 	Namespace string `json:"namespace,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the current state of the organization and the objects managed for it.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Adoption records the adoption of a pre-existing namespace.
+	// +optional
+	Adoption *NamespaceAdoption `json:"adoption,omitempty"`
+
+	// Quota mirrors the hard limits and the usage of the organization ResourceQuota.
+	// +optional
+	Quota *QuotaStatus `json:"quota,omitempty"`
+
+	// Namespaces is the number of namespaces labelled with the organization,
+	// counted when the organization has a maximum number of namespaces.
+	// +optional
+	Namespaces int32 `json:"namespaces,omitempty"`
+
+	// AutomationAccounts records the tokens issued to the automation accounts.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	AutomationAccounts []AutomationAccountStatus `json:"automationAccounts,omitempty"`
+
+	// Termination reports the progress of the deletion of the organization.
+	// +optional
+	Termination *TerminationStatus `json:"termination,omitempty"`
+}
+
+// TerminationStatus reports the progress of the deletion of an organization
+// and its namespace.
+type TerminationStatus struct {
+	// StartedAt is the time the deletion of the organization was requested.
+	StartedAt metav1.Time `json:"startedAt"`
+
+	// Duration is the time spent terminating so far.
+	// +optional
+	Duration metav1.Duration `json:"duration,omitempty"`
+
+	// NamespaceConditions mirrors the NamespaceContentRemaining and
+	// NamespaceFinalizersRemaining conditions of the terminating namespace.
+	// +optional
+	NamespaceConditions []corev1.NamespaceCondition `json:"namespaceConditions,omitempty"`
+
+	// Remaining counts the objects remaining in the namespace, by resource.
+	// +optional
+	Remaining map[string]int32 `json:"remaining,omitempty"`
+
+	// Stuck is set once the deletion takes longer than the operator threshold.
+	// +optional
+	Stuck bool `json:"stuck,omitempty"`
+}
+
+// AutomationAccountStatus records the token issued to an automation account.
+type AutomationAccountStatus struct {
+	// Name is the name of the account.
+	Name string `json:"name"`
+
+	// SecretName is the name of the Secret holding the kubeconfig of the account.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// RotatedAt is the time the current token was issued.
+	// +optional
+	RotatedAt *metav1.Time `json:"rotatedAt,omitempty"`
+
+	// ExpiresAt is the time the current token expires.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// QuotaStatus mirrors the status of the organization ResourceQuota.
+type QuotaStatus struct {
+	// Hard is the enforced hard limit for each resource.
+	// +optional
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+
+	// Used is the current usage of each resource.
+	// +optional
+	Used corev1.ResourceList `json:"used,omitempty"`
+
+	// Summary lists the usage against the hard limit of each resource, e.g.
+	// "limits.cpu: 1/4, pods: 3/10".
+	// +optional
+	Summary string `json:"summary,omitempty"`
+}
+
+// NamespaceAdoption records the adoption of a pre-existing namespace.
+type NamespaceAdoption struct {
+	// AdoptedAt is the time the namespace was adopted.
+	// +optional
+	AdoptedAt *metav1.Time `json:"adoptedAt,omitempty"`
+
+	// Inventory counts the objects found in the namespace at adoption time, by resource.
+	// +optional
+	Inventory map[string]int32 `json:"inventory,omitempty"`
+
+	// ConflictingOwners lists the owner references of the namespace that
+	// conflict with the organization. A conflicting controller reference
+	// prevents the adoption.
+	// +optional
+	ConflictingOwners []string `json:"conflictingOwners,omitempty"`
 }
 
 //nolint:revive
@@ -36,8 +489,18 @@ type OrganizationStatus struct {
 //nolint:revive
 //+kubebuilder:subresource:status
 //nolint:revive
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//nolint:revive
 //+kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".status.namespace"
 //nolint:revive
+//+kubebuilder:printcolumn:name="Class",type="string",JSONPath=".spec.className"
+//nolint:revive
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//nolint:revive
+//+kubebuilder:printcolumn:name="Suspended",type="boolean",JSONPath=".spec.suspended",priority=1
+//nolint:revive
+//+kubebuilder:printcolumn:name="Quota",type="string",JSONPath=".status.quota.summary"
+//nolint:revive
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //nolint:revive
 //+kubebuilder:resource:scope=Cluster,categories={common,giantswarm},shortName={org,orgs}
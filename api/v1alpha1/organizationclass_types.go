@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OrganizationClassSpec defines the defaults of the organizations of a class.
+// Each field is overridden by the same field set on the organization.
+type OrganizationClassSpec struct {
+	// Quota is the default ResourceQuota of the organization namespace.
+	// +optional
+	Quota *corev1.ResourceQuotaSpec `json:"quota,omitempty"`
+
+	// Limits are the default LimitRange items of the organization namespace.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Limits []corev1.LimitRangeItem `json:"limits,omitempty"`
+
+	// PodSecurity holds the default Pod Security Admission levels of the
+	// organization namespace, overridden field by field by the organization.
+	// +optional
+	PodSecurity *PodSecurity `json:"podSecurity,omitempty"`
+
+	// NetworkIsolation is the default network isolation mode of the
+	// organization namespace.
+	// +optional
+	NetworkIsolation NetworkIsolation `json:"networkIsolation,omitempty"`
+
+	// MaxNamespaces is the default maximum number of namespaces labelled
+	// with the organization.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxNamespaces *int32 `json:"maxNamespaces,omitempty"`
+}
+
+//nolint:revive
+//+kubebuilder:object:root=true
+//nolint:revive
+//+kubebuilder:printcolumn:name="Network Isolation",type="string",JSONPath=".spec.networkIsolation"
+//nolint:revive
+//+kubebuilder:printcolumn:name="Max Namespaces",type="integer",JSONPath=".spec.maxNamespaces"
+//nolint:revive
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//nolint:revive
+//+kubebuilder:resource:scope=Cluster,categories={common,giantswarm},shortName={orgclass,orgclasses}
+
+// OrganizationClass defines the defaults of the organizations referencing it,
+// such as a commercial tier.
+type OrganizationClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OrganizationClassSpec `json:"spec,omitempty"`
+}
+
+//nolint:revive
+//+kubebuilder:object:root=true
+
+// OrganizationClassList contains a list of OrganizationClass
+type OrganizationClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OrganizationClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OrganizationClass{}, &OrganizationClassList{})
+}
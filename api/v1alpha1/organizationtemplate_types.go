@@ -0,0 +1,147 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// OrganizationTemplateSpec defines the desired state of OrganizationTemplate
+type OrganizationTemplateSpec struct {
+	// OrganizationSelector selects the organizations the template is applied
+	// to. An empty selector selects every organization.
+	OrganizationSelector metav1.LabelSelector `json:"organizationSelector"`
+
+	// Resources are the manifests of the namespaced objects created in the
+	// namespace of each selected organization. String values are rendered as
+	// text/template with the fields .Name, .Namespace, .Labels and .Spec of
+	// the organization, e.g. "{{ .Spec.DisplayName }}". The namespace of the
+	// manifests is always set to the organization namespace.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Resources []runtime.RawExtension `json:"resources"`
+}
+
+// Condition types of OrganizationTemplate.
+const (
+	// TemplateReadyCondition reports whether the template is applied to every selected organization.
+	TemplateReadyCondition = "Ready"
+)
+
+// OrganizationTemplateStatus defines the observed state of OrganizationTemplate
+type OrganizationTemplateStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the current state of the template.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Selected is the number of organizations selected by the template.
+	// +optional
+	Selected int32 `json:"selected,omitempty"`
+
+	// Applied is the number of organizations the template is applied to.
+	// +optional
+	Applied int32 `json:"applied,omitempty"`
+
+	// Organizations records the outcome and the inventory of the template in
+	// each selected organization.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Organizations []TemplateOrganizationStatus `json:"organizations,omitempty"`
+}
+
+// TemplateOrganizationStatus records the template in one organization.
+type TemplateOrganizationStatus struct {
+	// Name is the name of the organization.
+	Name string `json:"name"`
+
+	// Namespace is the organization namespace the objects are applied to.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Applied reports whether every object of the template is applied.
+	Applied bool `json:"applied"`
+
+	// Message describes why the template is not applied.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Inventory lists the objects applied for the template, which are pruned
+	// once they are removed from it.
+	// +optional
+	Inventory []TemplateObjectReference `json:"inventory,omitempty"`
+}
+
+// TemplateObjectReference references an object applied for a template.
+type TemplateObjectReference struct {
+	// APIVersion is the API version of the object.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind is the kind of the object.
+	Kind string `json:"kind"`
+
+	// Name is the name of the object.
+	Name string `json:"name"`
+}
+
+//nolint:revive
+//+kubebuilder:object:root=true
+//nolint:revive
+//+kubebuilder:subresource:status
+//nolint:revive
+//+kubebuilder:printcolumn:name="Selected",type="integer",JSONPath=".status.selected"
+//nolint:revive
+//+kubebuilder:printcolumn:name="Applied",type="integer",JSONPath=".status.applied"
+//nolint:revive
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//nolint:revive
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//nolint:revive
+//+kubebuilder:resource:scope=Cluster,categories={common,giantswarm},shortName={orgtemplate,orgtemplates}
+
+// OrganizationTemplate holds manifests applied to the namespaces of the
+// organizations it selects.
+// Reconciled by organization-operator.
+type OrganizationTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OrganizationTemplateSpec   `json:"spec,omitempty"`
+	Status OrganizationTemplateStatus `json:"status,omitempty"`
+}
+
+//nolint:revive
+//+kubebuilder:object:root=true
+
+// OrganizationTemplateList contains a list of OrganizationTemplate
+type OrganizationTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OrganizationTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OrganizationTemplate{}, &OrganizationTemplateList{})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// OrganizationLabel is set on the objects managed for an organization and
+	// holds the organization name.
+	OrganizationLabel = "giantswarm.io/organization"
+
+	// ManagedByLabel is set on the objects managed by organization-operator.
+	ManagedByLabel = "giantswarm.io/managed-by"
+
+	// ManagedByValue is the value of ManagedByLabel on the objects managed by
+	// organization-operator.
+	ManagedByValue = "organization-operator"
+
+	// ReservedKeyPrefix prefixes the labels and annotations reserved to
+	// organization-operator.
+	ReservedKeyPrefix = "organization.giantswarm.io/"
+
+	// ConfirmDeletionAnnotation confirms the deletion of an organization with
+	// deletion protection when set to the organization name.
+	ConfirmDeletionAnnotation = ReservedKeyPrefix + "confirm-deletion"
+
+	// ReleasedAnnotation is set on a namespace released with the Orphan
+	// deletion policy and holds the name of the organization that released
+	// it. Released namespaces are only taken over again with
+	// spec.adoptNamespace.
+	ReleasedAnnotation = ReservedKeyPrefix + "released"
+
+	// TemplateLabel is set on the objects applied for an OrganizationTemplate
+	// and holds the template name.
+	TemplateLabel = ReservedKeyPrefix + "template"
+
+	// AutomationAccountLabel is set on the objects of an automation account
+	// and holds the account name.
+	AutomationAccountLabel = ReservedKeyPrefix + "automation-account"
+
+	// ReplicateToAnnotation is set on a Secret or ConfigMap to replicate it
+	// into the namespaces of the organizations matching the label selector it
+	// holds. An empty selector matches every organization.
+	ReplicateToAnnotation = ReservedKeyPrefix + "replicate-to"
+
+	// ReplicatedFromAnnotation is set on the replicas of a Secret or
+	// ConfigMap and holds the namespace/name of the source.
+	ReplicatedFromAnnotation = ReservedKeyPrefix + "replicated-from"
+
+	// ReplicaLabel is set to "true" on the replicas of a Secret or ConfigMap.
+	ReplicaLabel = ReservedKeyPrefix + "replica"
+
+	// SuspendedLabel is set to "true" on the namespace of a suspended
+	// organization. The suspension webhook only handles namespaces carrying it.
+	// It is also required by the node selector of suspended DaemonSets, so
+	// that their pods fit no node.
+	SuspendedLabel = ReservedKeyPrefix + "suspended"
+
+	// SuspendedReplicasAnnotation is set on the workloads scaled to zero for
+	// a suspended organization and holds their original replicas.
+	SuspendedReplicasAnnotation = ReservedKeyPrefix + "suspended-replicas"
+
+	// OriginalSuspendAnnotation is set on the Jobs and CronJobs suspended for
+	// a suspended organization and holds their original spec.suspend.
+	OriginalSuspendAnnotation = ReservedKeyPrefix + "original-suspend"
+
+	// PodSecurityLabelPrefix prefixes the Pod Security Admission namespace
+	// labels, which are managed through spec.podSecurity.
+	PodSecurityLabelPrefix = "pod-security.kubernetes.io/"
+)
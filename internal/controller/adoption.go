@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// operationResultAdopted is returned by ensureNamespace when a pre-existing
+// namespace has been adopted by the organization.
+const operationResultAdopted controllerutil.OperationResult = "adopted"
+
+// adoptionError is returned when a namespace that does not belong to the
+// organization cannot be adopted.
+type adoptionError struct {
+	reason  string
+	message string
+}
+
+func (e *adoptionError) Error() string {
+	return e.message
+}
+
+// adoptionFailureReason returns the condition reason of an adoption error.
+func adoptionFailureReason(err error) (string, bool) {
+	var adoptionErr *adoptionError
+	if errors.As(err, &adoptionErr) {
+		return adoptionErr.reason, true
+	}
+	return "", false
+}
+
+// ownsNamespace reports whether the namespace belongs to the organization:
+// either it is controlled by the organization, or it carries no controller
+// reference and was created for the organization, by a previous version of
+// the operator or before its controller reference was removed.
+func ownsNamespace(namespace *corev1.Namespace, organization *securityv1alpha1.Organization) bool {
+	if owner := metav1.GetControllerOf(namespace); owner != nil {
+		return owner.UID == organization.UID
+	}
+	return organization.Status.Namespace == namespace.Name || securityv1alpha1.IsLegacyNamespace(namespace, organization)
+}
+
+// adoptNamespace takes over a pre-existing namespace that does not belong to
+// the organization. Namespaces are only adopted when the organization opts in
+// with spec.adoptNamespace, and never when they are controlled by another
+// object. The objects found in the namespace and the owner references of the
+// namespace are recorded in the organization status.
+func (r *OrganizationReconciler) adoptNamespace(ctx context.Context, organization *securityv1alpha1.Organization, namespace *corev1.Namespace) error {
+	if !organization.Spec.AdoptNamespace {
+		return reconcile.TerminalError(&adoptionError{
+			reason: reasonNamespaceNotOwned,
+			message: fmt.Sprintf("namespace %s already exists and does not belong to the organization, set spec.adoptNamespace to adopt it",
+				namespace.Name),
+		})
+	}
+
+	var conflictingOwners []string
+	var conflictingController string
+	for _, ownerReference := range namespace.OwnerReferences {
+		owner := fmt.Sprintf("%s/%s (%s)", ownerReference.Kind, ownerReference.Name, ownerReference.APIVersion)
+		conflictingOwners = append(conflictingOwners, owner)
+		if ownerReference.Controller != nil && *ownerReference.Controller {
+			conflictingController = owner
+		}
+	}
+
+	if conflictingController != "" {
+		organization.Status.Adoption = &securityv1alpha1.NamespaceAdoption{ConflictingOwners: conflictingOwners}
+		r.Recorder.Eventf(organization, corev1.EventTypeWarning, eventReasonNamespaceOwnershipConflict,
+			"Namespace %s cannot be adopted because it is controlled by %s", namespace.Name, conflictingController)
+		return &adoptionError{
+			reason:  reasonNamespaceOwnershipConflict,
+			message: fmt.Sprintf("namespace %s cannot be adopted because it is controlled by %s", namespace.Name, conflictingController),
+		}
+	}
+
+	inventory, err := r.inventoryNamespace(ctx, namespace.Name)
+	if err != nil {
+		return err
+	}
+	if err := ctrl.SetControllerReference(organization, namespace, r.Scheme); err != nil {
+		return fmt.Errorf("unable to set controller reference on Namespace: %w", err)
+	}
+
+	now := metav1.Now()
+	organization.Status.Adoption = &securityv1alpha1.NamespaceAdoption{
+		AdoptedAt:         &now,
+		Inventory:         inventory,
+		ConflictingOwners: conflictingOwners,
+	}
+	log.FromContext(ctx).Info("Adopting namespace", "namespace", namespace.Name, "inventory", inventory)
+	r.Recorder.Eventf(organization, corev1.EventTypeNormal, eventReasonNamespaceAdopted,
+		"Adopted pre-existing namespace %s", namespace.Name)
+
+	return nil
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// DefaultAutomationTokenExpiration is the default lifetime of the tokens
+// issued to automation accounts.
+const DefaultAutomationTokenExpiration = 24 * time.Hour
+
+const (
+	automationAccountPrefix = "automation-"
+	kubeconfigSecretSuffix  = "-kubeconfig"
+
+	// kubeconfigSecretKey and tokenSecretKey are the keys of the kubeconfig
+	// Secret of an automation account.
+	kubeconfigSecretKey = "kubeconfig"
+	tokenSecretKey      = "token"
+
+	// The annotations of the kubeconfig Secret record the issued token, so
+	// that it is only rotated once it nears its expiry.
+	tokenIssuedAtAnnotation          = securityv1alpha1.ReservedKeyPrefix + "token-issued-at"
+	tokenExpiresAtAnnotation         = securityv1alpha1.ReservedKeyPrefix + "token-expires-at"
+	tokenExpirationSecondsAnnotation = securityv1alpha1.ReservedKeyPrefix + "token-expiration-seconds"
+
+	// tokenRotationRatio is the share of the token lifetime after which the
+	// token is rotated.
+	tokenRotationRatio = 0.8
+)
+
+// reconcileAutomationAccounts maintains a ServiceAccount, a RoleBinding and a
+// kubeconfig Secret per automation account in the organization namespace, and
+// prunes those of removed accounts. Tokens are requested from the TokenRequest
+// API and rotated before they expire. No kubeconfigs are issued unless the
+// API server address they point to is configured, as the address the operator
+// connects to is usually not reachable from outside of the cluster. The
+// outcome is recorded in the AutomationAccountsReady condition.
+func (r *OrganizationReconciler) reconcileAutomationAccounts(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	if len(organization.Spec.AutomationAccounts) > 0 && r.KubeconfigServer == "" {
+		setCondition(organization, securityv1alpha1.AutomationAccountsReadyCondition, metav1.ConditionFalse, reasonKubeconfigServerNotConfigured,
+			"Automation accounts require the API server address of their kubeconfigs, set with --kubeconfig-server")
+		return nil
+	}
+
+	roles := r.MemberRoles
+	if roles == nil {
+		roles = DefaultMemberRoles
+	}
+
+	var errs []error
+	var unknownRoles []string
+	statuses := make([]securityv1alpha1.AutomationAccountStatus, 0, len(organization.Spec.AutomationAccounts))
+	for _, account := range organization.Spec.AutomationAccounts {
+		clusterRole, ok := roles[account.Role]
+		if !ok {
+			unknownRoles = append(unknownRoles, account.Role)
+			statuses = appendAutomationAccountStatus(statuses, organization, account.Name)
+			continue
+		}
+		roleRef := rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+		}
+		status, err := r.ensureAutomationAccount(ctx, organization, account, roleRef)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("automation account %s: %w", account.Name, err))
+			statuses = appendAutomationAccountStatus(statuses, organization, account.Name)
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	if len(statuses) == 0 {
+		statuses = nil
+	}
+	organization.Status.AutomationAccounts = statuses
+
+	if err := r.pruneAutomationAccounts(ctx, organization); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		setCondition(organization, securityv1alpha1.AutomationAccountsReadyCondition, metav1.ConditionFalse,
+			reasonAutomationAccountsReconcileFailed, err.Error())
+		return err
+	}
+	if len(unknownRoles) > 0 {
+		setCondition(organization, securityv1alpha1.AutomationAccountsReadyCondition, metav1.ConditionFalse, reasonUnknownMemberRole,
+			fmt.Sprintf("Unknown automation account roles: %s", strings.Join(unknownRoles, ", ")))
+		return nil
+	}
+	setCondition(organization, securityv1alpha1.AutomationAccountsReadyCondition, metav1.ConditionTrue, reasonAutomationAccountsReconciled,
+		fmt.Sprintf("Kubeconfigs of %d automation accounts are reconciled", len(organization.Spec.AutomationAccounts)))
+	return nil
+}
+
+// appendAutomationAccountStatus appends the recorded status of the account,
+// if any, so that the status of accounts failing to reconcile is kept.
+func appendAutomationAccountStatus(statuses []securityv1alpha1.AutomationAccountStatus, organization *securityv1alpha1.Organization, name string) []securityv1alpha1.AutomationAccountStatus {
+	for _, status := range organization.Status.AutomationAccounts {
+		if status.Name == name {
+			return append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// ensureAutomationAccount creates or updates the ServiceAccount, RoleBinding
+// and kubeconfig Secret of the account, issuing a new token when the current
+// one is missing, nears its expiry or was requested with another lifetime.
+// Existing objects that are not controlled by the organization are refused
+// rather than taken over.
+func (r *OrganizationReconciler) ensureAutomationAccount(ctx context.Context, organization *securityv1alpha1.Organization, account securityv1alpha1.AutomationAccount, roleRef rbacv1.RoleRef) (securityv1alpha1.AutomationAccountStatus, error) {
+	name := automationAccountPrefix + account.Name
+	namespace := organization.Status.Namespace
+	labels := map[string]string{securityv1alpha1.AutomationAccountLabel: account.Name}
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+	_, err := r.ensureObject(ctx, serviceAccount, func() error {
+		if serviceAccount.ResourceVersion != "" && !metav1.IsControlledBy(serviceAccount, organization) {
+			return fmt.Errorf("ServiceAccount %s exists and is not controlled by the organization", serviceAccount.Name)
+		}
+		serviceAccountLabels := childLabels(serviceAccount.GetLabels(), organization)
+		serviceAccountLabels[securityv1alpha1.AutomationAccountLabel] = account.Name
+		serviceAccount.SetLabels(serviceAccountLabels)
+		return ctrl.SetControllerReference(organization, serviceAccount, r.Scheme)
+	})
+	if err != nil {
+		return securityv1alpha1.AutomationAccountStatus{}, err
+	}
+
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}}
+	if err := r.ensureRoleBinding(ctx, organization, name, labels, roleRef, subjects); err != nil {
+		return securityv1alpha1.AutomationAccountStatus{}, err
+	}
+
+	expirationSeconds := int64(r.automationTokenExpiration().Seconds())
+	if account.ExpirationSeconds != nil {
+		expirationSeconds = *account.ExpirationSeconds
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name + kubeconfigSecretSuffix, Namespace: namespace},
+	}
+	var kubeconfig, token []byte
+	var issuedAt, expiresAt time.Time
+	_, err = r.ensureObject(ctx, secret, func() error {
+		if secret.ResourceVersion != "" && !metav1.IsControlledBy(secret, organization) {
+			return fmt.Errorf("kubeconfig Secret %s exists and is not controlled by the organization", secret.Name)
+		}
+
+		var ok bool
+		issuedAt, expiresAt, ok = issuedToken(secret, expirationSeconds)
+		if !ok || !time.Now().Before(tokenRotationTime(issuedAt, expiresAt)) {
+			tokenRequest := &authenticationv1.TokenRequest{
+				Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+			}
+			if err := r.SubResource("token").Create(ctx, serviceAccount, tokenRequest); err != nil {
+				return fmt.Errorf("failed to request token of ServiceAccount %s: %w", serviceAccount.Name, err)
+			}
+			issuedAt = time.Now()
+			expiresAt = tokenRequest.Status.ExpirationTimestamp.Time
+			token = []byte(tokenRequest.Status.Token)
+			if kubeconfig, err = r.kubeconfig(organization, name, tokenRequest.Status.Token); err != nil {
+				return err
+			}
+		}
+
+		secretLabels := childLabels(secret.GetLabels(), organization)
+		secretLabels[securityv1alpha1.AutomationAccountLabel] = account.Name
+		secret.SetLabels(secretLabels)
+		if token != nil {
+			annotations := secret.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[tokenIssuedAtAnnotation] = issuedAt.UTC().Format(time.RFC3339)
+			annotations[tokenExpiresAtAnnotation] = expiresAt.UTC().Format(time.RFC3339)
+			annotations[tokenExpirationSecondsAnnotation] = strconv.FormatInt(expirationSeconds, 10)
+			secret.SetAnnotations(annotations)
+			secret.Type = corev1.SecretTypeOpaque
+			secret.Data = map[string][]byte{
+				kubeconfigSecretKey: kubeconfig,
+				tokenSecretKey:      token,
+			}
+		}
+		return ctrl.SetControllerReference(organization, secret, r.Scheme)
+	})
+	if err != nil {
+		return securityv1alpha1.AutomationAccountStatus{}, err
+	}
+
+	rotatedAt := metav1.NewTime(issuedAt.Truncate(time.Second))
+	expires := metav1.NewTime(expiresAt.Truncate(time.Second))
+	return securityv1alpha1.AutomationAccountStatus{
+		Name:       account.Name,
+		SecretName: secret.Name,
+		RotatedAt:  &rotatedAt,
+		ExpiresAt:  &expires,
+	}, nil
+}
+
+// issuedToken returns the issue and expiry time of the token held by the
+// kubeconfig Secret, and whether it was requested with the given lifetime.
+func issuedToken(secret *corev1.Secret, expirationSeconds int64) (time.Time, time.Time, bool) {
+	annotations := secret.GetAnnotations()
+	if annotations[tokenExpirationSecondsAnnotation] != strconv.FormatInt(expirationSeconds, 10) || len(secret.Data[kubeconfigSecretKey]) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	issuedAt, err := time.Parse(time.RFC3339, annotations[tokenIssuedAtAnnotation])
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, annotations[tokenExpiresAtAnnotation])
+	if err != nil || !expiresAt.After(issuedAt) {
+		return time.Time{}, time.Time{}, false
+	}
+	return issuedAt, expiresAt, true
+}
+
+// tokenRotationTime returns the time a token issued and expiring at the given
+// times is rotated.
+func tokenRotationTime(issuedAt, expiresAt time.Time) time.Time {
+	return issuedAt.Add(time.Duration(float64(expiresAt.Sub(issuedAt)) * tokenRotationRatio))
+}
+
+// nextTokenRotation returns the earliest time a token of the automation
+// accounts of the organization is rotated, and false when it has no tokens.
+func nextTokenRotation(organization *securityv1alpha1.Organization) (time.Time, bool) {
+	var next time.Time
+	for _, status := range organization.Status.AutomationAccounts {
+		if status.RotatedAt == nil || status.ExpiresAt == nil {
+			continue
+		}
+		rotation := tokenRotationTime(status.RotatedAt.Time, status.ExpiresAt.Time)
+		if next.IsZero() || rotation.Before(next) {
+			next = rotation
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// kubeconfig renders the kubeconfig of the ServiceAccount, defaulting to
+// the organization namespace.
+func (r *OrganizationReconciler) kubeconfig(organization *securityv1alpha1.Organization, user, token string) ([]byte, error) {
+	config := clientcmdapi.NewConfig()
+	config.Clusters[organization.Name] = &clientcmdapi.Cluster{
+		Server:                   r.KubeconfigServer,
+		CertificateAuthorityData: r.KubeconfigCA,
+	}
+	config.AuthInfos[user] = &clientcmdapi.AuthInfo{Token: token}
+	config.Contexts[organization.Name] = &clientcmdapi.Context{
+		Cluster:   organization.Name,
+		AuthInfo:  user,
+		Namespace: organization.Status.Namespace,
+	}
+	config.CurrentContext = organization.Name
+
+	kubeconfig, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write kubeconfig of %s: %w", user, err)
+	}
+	return kubeconfig, nil
+}
+
+// pruneAutomationAccounts deletes the ServiceAccounts, RoleBindings and
+// Secrets of the automation accounts removed from the organization.
+func (r *OrganizationReconciler) pruneAutomationAccounts(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	accounts := map[string]bool{}
+	for _, account := range organization.Spec.AutomationAccounts {
+		accounts[account.Name] = true
+	}
+
+	for _, list := range []client.ObjectList{&corev1.ServiceAccountList{}, &rbacv1.RoleBindingList{}, &corev1.SecretList{}} {
+		if err := r.List(ctx, list, client.InNamespace(organization.Status.Namespace), client.HasLabels{securityv1alpha1.AutomationAccountLabel},
+			client.MatchingLabels{securityv1alpha1.OrganizationLabel: organization.Name}); err != nil {
+			return fmt.Errorf("failed to list automation account objects: %w", err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			object := item.(client.Object)
+			if accounts[object.GetLabels()[securityv1alpha1.AutomationAccountLabel]] || !metav1.IsControlledBy(object, organization) {
+				continue
+			}
+			if err := r.Delete(ctx, object); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete %s %s: %w", r.kindOf(object), object.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *OrganizationReconciler) automationTokenExpiration() time.Duration {
+	if r.AutomationTokenExpiration == 0 {
+		return DefaultAutomationTokenExpiration
+	}
+	return r.AutomationTokenExpiration
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Organization automation accounts", func() {
+	var (
+		ctx               context.Context
+		tokenRequests     []int64
+		fakeClient        client.Client
+		reconciler        *OrganizationReconciler
+		org               *securityv1alpha1.Organization
+		expirationSeconds int64
+	)
+
+	reconcileOrganization := func() (reconcile.Result, error) {
+		return reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "test-automation"},
+		})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		tokenRequests = nil
+		fakeClient = interceptor.NewClient(newFakeClient().(client.WithWatch), interceptor.Funcs{
+			SubResourceCreate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+				Expect(subResourceName).To(Equal("token"))
+				tokenRequest := subResource.(*authenticationv1.TokenRequest)
+				tokenRequests = append(tokenRequests, *tokenRequest.Spec.ExpirationSeconds)
+				tokenRequest.Status.Token = fmt.Sprintf("token-%d", len(tokenRequests))
+				tokenRequest.Status.ExpirationTimestamp = metav1.NewTime(time.Now().Add(time.Duration(*tokenRequest.Spec.ExpirationSeconds) * time.Second))
+				return nil
+			},
+		})
+		reconciler = &OrganizationReconciler{
+			Client:           fakeClient,
+			Scheme:           fakeClient.Scheme(),
+			Recorder:         &record.FakeRecorder{},
+			KubeconfigServer: "https://api.example.com",
+			KubeconfigCA:     []byte("ca"),
+		}
+
+		expirationSeconds = 3600
+		org = &securityv1alpha1.Organization{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-automation"},
+			Spec: securityv1alpha1.OrganizationSpec{
+				AutomationAccounts: []securityv1alpha1.AutomationAccount{
+					{Name: "ci", Role: "editor", ExpirationSeconds: &expirationSeconds},
+					{Name: "backup", Role: "viewer"},
+				},
+			},
+		}
+	})
+
+	Context("When an Organization has automation accounts", func() {
+		var result reconcile.Result
+
+		BeforeEach(func() {
+			Expect(fakeClient.Create(ctx, org)).To(Succeed())
+			var err error
+			result, err = reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should create a ServiceAccount bound to the role of each account", func() {
+			serviceAccount := &corev1.ServiceAccount{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-ci"}, serviceAccount)).To(Succeed())
+			Expect(metav1.GetControllerOf(serviceAccount)).NotTo(BeNil())
+
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-ci"}, roleBinding)).To(Succeed())
+			Expect(roleBinding.RoleRef.Name).To(Equal("edit"))
+			Expect(roleBinding.Subjects).To(Equal([]rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: "automation-ci", Namespace: "org-test-automation"},
+			}))
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-backup"}, roleBinding)).To(Succeed())
+			Expect(roleBinding.RoleRef.Name).To(Equal("view"))
+		})
+
+		It("Should issue kubeconfigs with tokens of the requested lifetime", func() {
+			Expect(tokenRequests).To(Equal([]int64{3600, 86400}))
+
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-ci-kubeconfig"}, secret)).To(Succeed())
+			kubeconfig, err := clientcmd.Load(secret.Data["kubeconfig"])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kubeconfig.Clusters["test-automation"].Server).To(Equal("https://api.example.com"))
+			Expect(kubeconfig.Clusters["test-automation"].CertificateAuthorityData).To(Equal([]byte("ca")))
+			Expect(kubeconfig.AuthInfos["automation-ci"].Token).To(Equal("token-1"))
+			Expect(kubeconfig.Contexts["test-automation"].Namespace).To(Equal("org-test-automation"))
+		})
+
+		It("Should record the accounts in the status and requeue before the first token expires", func() {
+			Expect(result.RequeueAfter).To(BeNumerically("~", 48*time.Minute, time.Minute))
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-automation"}, org)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(org.Status.Conditions, securityv1alpha1.AutomationAccountsReadyCondition)).To(BeTrue())
+			Expect(org.Status.AutomationAccounts).To(HaveLen(2))
+			Expect(org.Status.AutomationAccounts[0].Name).To(Equal("ci"))
+			Expect(org.Status.AutomationAccounts[0].SecretName).To(Equal("automation-ci-kubeconfig"))
+			Expect(org.Status.AutomationAccounts[0].RotatedAt).NotTo(BeNil())
+			Expect(org.Status.AutomationAccounts[0].ExpiresAt).NotTo(BeNil())
+		})
+
+		It("Should keep the tokens until they near their expiry", func() {
+			_, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tokenRequests).To(HaveLen(2))
+		})
+
+		It("Should rotate a token once it nears its expiry", func() {
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-ci-kubeconfig"}, secret)).To(Succeed())
+			secret.Annotations[tokenIssuedAtAnnotation] = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+			secret.Annotations[tokenExpiresAtAnnotation] = time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339)
+			Expect(fakeClient.Update(ctx, secret)).To(Succeed())
+
+			_, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tokenRequests).To(HaveLen(3))
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-ci-kubeconfig"}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("token", []byte("token-3")))
+		})
+
+		It("Should prune the objects of removed accounts", func() {
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-automation"}, org)).To(Succeed())
+			org.Spec.AutomationAccounts = org.Spec.AutomationAccounts[:1]
+			Expect(fakeClient.Update(ctx, org)).To(Succeed())
+			_, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, object := range []client.Object{&corev1.ServiceAccount{}, &rbacv1.RoleBinding{}} {
+				err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-backup"}, object)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			}
+			err = fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-backup-kubeconfig"}, &corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-automation"}, org)).To(Succeed())
+			Expect(org.Status.AutomationAccounts).To(HaveLen(1))
+		})
+
+		It("Should report roles unknown to the operator", func() {
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-automation"}, org)).To(Succeed())
+			org.Spec.AutomationAccounts = append(org.Spec.AutomationAccounts, securityv1alpha1.AutomationAccount{Name: "deploy", Role: "owner"})
+			Expect(fakeClient.Update(ctx, org)).To(Succeed())
+			_, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-automation"}, org)).To(Succeed())
+			automationAccountsReady := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.AutomationAccountsReadyCondition)
+			Expect(automationAccountsReady).NotTo(BeNil())
+			Expect(automationAccountsReady.Status).To(Equal(metav1.ConditionFalse))
+			Expect(automationAccountsReady.Reason).To(Equal("UnknownMemberRole"))
+			Expect(automationAccountsReady.Message).To(ContainSubstring("owner"))
+			err = fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-deploy"}, &corev1.ServiceAccount{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("When the API server address of the kubeconfigs is not configured", func() {
+		It("Should not issue kubeconfigs", func() {
+			reconciler.KubeconfigServer = ""
+			Expect(fakeClient.Create(ctx, org)).To(Succeed())
+			_, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tokenRequests).To(BeEmpty())
+			err = fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-ci-kubeconfig"}, &corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-automation"}, org)).To(Succeed())
+			automationAccountsReady := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.AutomationAccountsReadyCondition)
+			Expect(automationAccountsReady).NotTo(BeNil())
+			Expect(automationAccountsReady.Status).To(Equal(metav1.ConditionFalse))
+			Expect(automationAccountsReady.Reason).To(Equal("KubeconfigServerNotConfigured"))
+		})
+	})
+
+	Context("When objects of an automation account are not controlled by the Organization", func() {
+		expectRefused := func() {
+			Expect(fakeClient.Create(ctx, org)).To(Succeed())
+			_, err := reconcileOrganization()
+			Expect(err).To(MatchError(ContainSubstring("exists and is not controlled by the organization")))
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-automation"}, org)).To(Succeed())
+			automationAccountsReady := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.AutomationAccountsReadyCondition)
+			Expect(automationAccountsReady).NotTo(BeNil())
+			Expect(automationAccountsReady.Status).To(Equal(metav1.ConditionFalse))
+			Expect(automationAccountsReady.Reason).To(Equal("AutomationAccountsReconcileFailed"))
+		}
+
+		It("Should refuse the ServiceAccount", func() {
+			Expect(fakeClient.Create(ctx, &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "automation-ci", Namespace: "org-test-automation"},
+			})).To(Succeed())
+			expectRefused()
+
+			serviceAccount := &corev1.ServiceAccount{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-ci"}, serviceAccount)).To(Succeed())
+			Expect(serviceAccount.OwnerReferences).To(BeEmpty())
+			Expect(serviceAccount.Labels).To(BeEmpty())
+			Expect(tokenRequests).To(Equal([]int64{86400}))
+		})
+
+		It("Should refuse the RoleBinding", func() {
+			Expect(fakeClient.Create(ctx, &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "automation-ci", Namespace: "org-test-automation"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "auditors"}},
+			})).To(Succeed())
+			expectRefused()
+
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-ci"}, roleBinding)).To(Succeed())
+			Expect(roleBinding.OwnerReferences).To(BeEmpty())
+			Expect(roleBinding.RoleRef.Name).To(Equal("view"))
+			Expect(roleBinding.Subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "auditors"}}))
+		})
+
+		It("Should refuse the kubeconfig Secret", func() {
+			Expect(fakeClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "automation-ci-kubeconfig", Namespace: "org-test-automation"},
+				Data:       map[string][]byte{"kubeconfig": []byte("foreign")},
+			})).To(Succeed())
+			expectRefused()
+
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-automation", Name: "automation-ci-kubeconfig"}, secret)).To(Succeed())
+			Expect(secret.OwnerReferences).To(BeEmpty())
+			Expect(secret.Data).To(Equal(map[string][]byte{"kubeconfig": []byte("foreign")}))
+		})
+	})
+})
@@ -0,0 +1,127 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// reconcileChildren reconciles the objects managed for the organization once
+// its namespace is reconciled. The objects are reconciled independently of
+// each other, so that one failing does not block the others.
+func (r *OrganizationReconciler) reconcileChildren(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	var errs []error
+	for _, child := range []struct {
+		condition string
+		reconcile func(context.Context, *securityv1alpha1.Organization) error
+	}{
+		{securityv1alpha1.MembersReadyCondition, r.reconcileMembers},
+		{securityv1alpha1.QuotaReadyCondition, r.reconcileQuota},
+		{securityv1alpha1.NetworkIsolationReadyCondition, r.reconcileNetworkIsolation},
+		{securityv1alpha1.AutomationAccountsReadyCondition, r.reconcileAutomationAccounts},
+	} {
+		if err := child.reconcile(ctx, organization); err != nil {
+			r.recordConditionFailure(organization, child.condition, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ensureObject creates the object, or merge patches it if mutate changes it.
+// The object only needs its name and namespace set, mutate sets everything
+// else the operator manages on it.
+func (r *OrganizationReconciler) ensureObject(ctx context.Context, object client.Object, mutate func() error) (controllerutil.OperationResult, error) {
+	kind := r.kindOf(object)
+
+	err := r.Get(ctx, client.ObjectKeyFromObject(object), object)
+	if apierrors.IsNotFound(err) {
+		if err := mutate(); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		if err := r.Create(ctx, object, client.FieldOwner(fieldManager)); err != nil {
+			return controllerutil.OperationResultNone, fmt.Errorf("failed to create %s %s: %w", kind, object.GetName(), err)
+		}
+		return controllerutil.OperationResultCreated, nil
+	}
+	if err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("failed to get %s %s: %w", kind, object.GetName(), err)
+	}
+
+	original := object.DeepCopyObject().(client.Object)
+	if err := mutate(); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if equality.Semantic.DeepEqual(original, object) {
+		return controllerutil.OperationResultNone, nil
+	}
+	if err := r.Patch(ctx, object, client.MergeFrom(original), client.FieldOwner(fieldManager)); err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("failed to patch %s %s: %w", kind, object.GetName(), err)
+	}
+	return controllerutil.OperationResultUpdated, nil
+}
+
+// deleteObject deletes the object if it exists and is controlled by the
+// organization. The object only needs its name and namespace set.
+func (r *OrganizationReconciler) deleteObject(ctx context.Context, organization *securityv1alpha1.Organization, object client.Object) error {
+	kind := r.kindOf(object)
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get %s %s: %w", kind, object.GetName(), err)
+	}
+	if !metav1.IsControlledBy(object, organization) {
+		return nil
+	}
+	if err := r.Delete(ctx, object); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete %s %s: %w", kind, object.GetName(), err)
+	}
+	return nil
+}
+
+// childLabels sets the labels identifying the objects managed for the
+// organization on the given labels.
+func childLabels(labels map[string]string, organization *securityv1alpha1.Organization) map[string]string {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[securityv1alpha1.OrganizationLabel] = organization.Name
+	labels[securityv1alpha1.ManagedByLabel] = managedByValue
+	return labels
+}
+
+// kindOf returns the kind of the object for use in messages.
+func (r *OrganizationReconciler) kindOf(object client.Object) string {
+	gvk, err := apiutil.GVKForObject(object, r.Scheme)
+	if err != nil {
+		return fmt.Sprintf("%T", object)
+	}
+	return gvk.Kind
+}
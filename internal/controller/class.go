@@ -0,0 +1,164 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// DefaultClassRolloutInterval is the default interval between the
+// organizations reconciled for a changed OrganizationClass.
+const DefaultClassRolloutInterval = time.Second
+
+// applyClassDefaults fills the fields the organization leaves unset with the
+// defaults of its class. The resulting spec is only used to reconcile the
+// organization and is never written back.
+func applyClassDefaults(organization *securityv1alpha1.Organization, class *securityv1alpha1.OrganizationClass) {
+	if class == nil {
+		return
+	}
+	spec := &organization.Spec
+	if spec.Quota == nil && class.Spec.Quota != nil {
+		spec.Quota = class.Spec.Quota.DeepCopy()
+	}
+	if len(spec.Limits) == 0 && len(class.Spec.Limits) > 0 {
+		spec.Limits = class.DeepCopy().Spec.Limits
+	}
+	if spec.NetworkIsolation == "" {
+		spec.NetworkIsolation = class.Spec.NetworkIsolation
+	}
+	if spec.MaxNamespaces == nil && class.Spec.MaxNamespaces != nil {
+		maxNamespaces := *class.Spec.MaxNamespaces
+		spec.MaxNamespaces = &maxNamespaces
+	}
+	if class.Spec.PodSecurity != nil {
+		podSecurity := class.Spec.PodSecurity.DeepCopy()
+		if override := spec.PodSecurity; override != nil {
+			if override.Enforce != "" {
+				podSecurity.Enforce = override.Enforce
+			}
+			if override.EnforceVersion != "" {
+				podSecurity.EnforceVersion = override.EnforceVersion
+			}
+			if override.Audit != "" {
+				podSecurity.Audit = override.Audit
+			}
+			if override.AuditVersion != "" {
+				podSecurity.AuditVersion = override.AuditVersion
+			}
+			if override.Warn != "" {
+				podSecurity.Warn = override.Warn
+			}
+			if override.WarnVersion != "" {
+				podSecurity.WarnVersion = override.WarnVersion
+			}
+		}
+		spec.PodSecurity = podSecurity
+	}
+}
+
+// setClassCondition records whether the class referenced by the organization
+// exists in the ClassReady condition, and reports whether the organization
+// can be reconciled. Organizations are not reconciled without their class, so
+// that the defaults it provides are not removed in the meantime.
+func setClassCondition(organization *securityv1alpha1.Organization, class *securityv1alpha1.OrganizationClass) bool {
+	switch {
+	case organization.Spec.ClassName == "":
+		meta.RemoveStatusCondition(&organization.Status.Conditions, securityv1alpha1.ClassReadyCondition)
+		return true
+	case class == nil:
+		setCondition(organization, securityv1alpha1.ClassReadyCondition, metav1.ConditionFalse, reasonClassNotFound,
+			fmt.Sprintf("OrganizationClass %s does not exist", organization.Spec.ClassName))
+		return false
+	default:
+		setCondition(organization, securityv1alpha1.ClassReadyCondition, metav1.ConditionTrue, reasonClassResolved,
+			fmt.Sprintf("Defaults of OrganizationClass %s are applied", class.Name))
+		return true
+	}
+}
+
+// resolveClass returns the class of the organization and applies its
+// defaults. It returns nil when the organization references no class or a
+// missing one, which is reported by setClassCondition.
+func (r *OrganizationReconciler) resolveClass(ctx context.Context, organization *securityv1alpha1.Organization) (*securityv1alpha1.OrganizationClass, error) {
+	if organization.Spec.ClassName == "" {
+		return nil, nil
+	}
+	class := &securityv1alpha1.OrganizationClass{}
+	err := r.Get(ctx, client.ObjectKey{Name: organization.Spec.ClassName}, class)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OrganizationClass %s: %w", organization.Spec.ClassName, err)
+	}
+	applyClassDefaults(organization, class)
+	return class, nil
+}
+
+// classRolloutHandler enqueues the organizations of a created, changed or
+// deleted OrganizationClass, spaced by the class rollout interval so that a
+// class change rolls out gradually.
+func (r *OrganizationReconciler) classRolloutHandler() handler.EventHandler {
+	rollout := func(ctx context.Context, class client.Object, queue workqueue.RateLimitingInterface) {
+		organizations := &securityv1alpha1.OrganizationList{}
+		if err := r.List(ctx, organizations); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list organizations of class", "class", class.GetName())
+			return
+		}
+
+		interval := r.ClassRolloutInterval
+		if interval == 0 {
+			interval = DefaultClassRolloutInterval
+		}
+		var delay time.Duration
+		for _, organization := range organizations.Items {
+			if organization.Spec.ClassName != class.GetName() {
+				continue
+			}
+			queue.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{Name: organization.Name}}, delay)
+			delay += interval
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+			rollout(ctx, e.Object, queue)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+			rollout(ctx, e.ObjectNew, queue)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+			rollout(ctx, e.Object, queue)
+		},
+	}
+}
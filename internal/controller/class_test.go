@@ -0,0 +1,196 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// delayRecordingQueue records the delays of the requests added to it.
+type delayRecordingQueue struct {
+	workqueue.RateLimitingInterface
+	delays map[string]time.Duration
+}
+
+func (q *delayRecordingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.delays[item.(reconcile.Request).Name] = duration
+}
+
+var _ = Describe("Organization class", func() {
+	Context("When an Organization references an OrganizationClass", func() {
+		It("Should apply the class defaults unless the Organization overrides them", func() {
+			ctx := context.Background()
+			maxNamespaces := int32(1)
+			fakeClient := newFakeClient(&securityv1alpha1.OrganizationClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+				Spec: securityv1alpha1.OrganizationClassSpec{
+					Quota: &corev1.ResourceQuotaSpec{
+						Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("20")},
+					},
+					PodSecurity: &securityv1alpha1.PodSecurity{
+						Enforce: securityv1alpha1.PodSecurityLevelBaseline,
+						Warn:    securityv1alpha1.PodSecurityLevelBaseline,
+					},
+					NetworkIsolation: securityv1alpha1.NetworkIsolationEnabled,
+					MaxNamespaces:    &maxNamespaces,
+				},
+			})
+			reconciler := &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+			reconcileOrganization := func() {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "test-class"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-class"},
+				Spec: securityv1alpha1.OrganizationSpec{
+					ClassName: "standard",
+					PodSecurity: &securityv1alpha1.PodSecurity{
+						Warn: securityv1alpha1.PodSecurityLevelRestricted,
+					},
+				},
+			}
+			Expect(fakeClient.Create(ctx, org)).To(Succeed())
+			reconcileOrganization()
+
+			resourceQuota := &corev1.ResourceQuota{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-class", Name: "organization-quota"}, resourceQuota)).To(Succeed())
+			Expect(resourceQuota.Spec.Hard).To(HaveKey(corev1.ResourcePods))
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-class", Name: "organization-default-deny-ingress"}, &networkingv1.NetworkPolicy{})).To(Succeed())
+
+			namespace := &corev1.Namespace{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "org-test-class"}, namespace)).To(Succeed())
+			Expect(namespace.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "baseline"))
+			Expect(namespace.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/warn", "restricted"))
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-class"}, org)).To(Succeed())
+			Expect(org.Spec.Quota).To(BeNil())
+			Expect(org.Status.Namespaces).To(Equal(int32(1)))
+			Expect(meta.IsStatusConditionTrue(org.Status.Conditions, securityv1alpha1.ClassReadyCondition)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(org.Status.Conditions, securityv1alpha1.ReadyCondition)).To(BeTrue())
+
+			By("Exceeding the maximum number of namespaces of the class")
+			Expect(fakeClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test-class-extra",
+					Labels: map[string]string{securityv1alpha1.OrganizationLabel: "test-class"},
+				},
+			})).To(Succeed())
+			reconcileOrganization()
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-class"}, org)).To(Succeed())
+			Expect(org.Status.Namespaces).To(Equal(int32(2)))
+			quotaReady := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.QuotaReadyCondition)
+			Expect(quotaReady).NotTo(BeNil())
+			Expect(quotaReady.Status).To(Equal(metav1.ConditionFalse))
+			Expect(quotaReady.Reason).To(Equal("NamespaceLimitExceeded"))
+
+			By("Overriding the maximum number of namespaces")
+			maxNamespaces = 2
+			org.Spec.MaxNamespaces = &maxNamespaces
+			Expect(fakeClient.Update(ctx, org)).To(Succeed())
+			reconcileOrganization()
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-class"}, org)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(org.Status.Conditions, securityv1alpha1.QuotaReadyCondition)).To(BeTrue())
+		})
+
+		It("Should wait for a missing OrganizationClass", func() {
+			ctx := context.Background()
+			fakeClient := newFakeClient(&securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-missing-class"},
+				Spec:       securityv1alpha1.OrganizationSpec{ClassName: "enterprise"},
+			})
+			reconciler := &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-missing-class"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "org-test-missing-class"}, &corev1.Namespace{})).NotTo(Succeed())
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-missing-class"}, org)).To(Succeed())
+			classReady := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.ClassReadyCondition)
+			Expect(classReady).NotTo(BeNil())
+			Expect(classReady.Reason).To(Equal("ClassNotFound"))
+			Expect(meta.IsStatusConditionFalse(org.Status.Conditions, securityv1alpha1.ReadyCondition)).To(BeTrue())
+		})
+	})
+
+	Context("When an OrganizationClass changes", func() {
+		It("Should roll the change out to its organizations one interval apart", func() {
+			ctx := context.Background()
+			class := &securityv1alpha1.OrganizationClass{ObjectMeta: metav1.ObjectMeta{Name: "free"}}
+			fakeClient := newFakeClient(
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "first"},
+					Spec:       securityv1alpha1.OrganizationSpec{ClassName: "free"},
+				},
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "second"},
+					Spec:       securityv1alpha1.OrganizationSpec{ClassName: "free"},
+				},
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "other"},
+					Spec:       securityv1alpha1.OrganizationSpec{ClassName: "enterprise"},
+				},
+			)
+			reconciler := &OrganizationReconciler{
+				Client:               fakeClient,
+				Scheme:               fakeClient.Scheme(),
+				ClassRolloutInterval: 5 * time.Second,
+			}
+
+			queue := &delayRecordingQueue{delays: map[string]time.Duration{}}
+			reconciler.classRolloutHandler().Update(ctx, event.UpdateEvent{ObjectOld: class, ObjectNew: class}, queue)
+
+			Expect(queue.delays).To(Equal(map[string]time.Duration{
+				"first":  0,
+				"second": 5 * time.Second,
+			}))
+		})
+	})
+})
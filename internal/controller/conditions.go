@@ -0,0 +1,124 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// Condition reasons used by the Organization controller.
+const (
+	reasonReconciled                        = "Reconciled"
+	reasonNotReady                          = "NotReady"
+	reasonDeleting                          = "Deleting"
+	reasonDeletionTimedOut                  = "DeletionTimedOut"
+	reasonNamespaceReconciled               = "NamespaceReconciled"
+	reasonNamespaceReconcileFailed          = "NamespaceReconcileFailed"
+	reasonNamespaceDeleting                 = "NamespaceDeleting"
+	reasonNamespaceReleasing                = "NamespaceReleasing"
+	reasonNamespaceNotOwned                 = "NamespaceNotOwned"
+	reasonNamespaceOwnershipConflict        = "NamespaceOwnershipConflict"
+	reasonMembersReconciled                 = "MembersReconciled"
+	reasonMembersReconcileFailed            = "MembersReconcileFailed"
+	reasonUnknownMemberRole                 = "UnknownMemberRole"
+	reasonQuotaReconciled                   = "QuotaReconciled"
+	reasonQuotaReconcileFailed              = "QuotaReconcileFailed"
+	reasonNetworkIsolationReconciled        = "NetworkIsolationReconciled"
+	reasonNetworkIsolationDisabled          = "NetworkIsolationDisabled"
+	reasonNetworkIsolationReconcileFailed   = "NetworkIsolationReconcileFailed"
+	reasonPodSecurityReconciled             = "PodSecurityReconciled"
+	reasonPodSecurityViolations             = "PodSecurityViolations"
+	reasonClassResolved                     = "ClassResolved"
+	reasonClassNotFound                     = "ClassNotFound"
+	reasonNamespaceLimitExceeded            = "NamespaceLimitExceeded"
+	reasonAutomationAccountsReconciled      = "AutomationAccountsReconciled"
+	reasonAutomationAccountsReconcileFailed = "AutomationAccountsReconcileFailed"
+	reasonKubeconfigServerNotConfigured     = "KubeconfigServerNotConfigured"
+	reasonSuspended                         = "Suspended"
+	reasonSuspensionFailed                  = "SuspensionFailed"
+	reasonResumeFailed                      = "ResumeFailed"
+)
+
+// readinessConditions lists the conditions that must all be True for an
+// organization to be reported as Ready. Conditions that are not set yet are
+// ignored so that optional child objects do not block readiness.
+var readinessConditions = []string{
+	securityv1alpha1.ClassReadyCondition,
+	securityv1alpha1.NamespaceReadyCondition,
+	securityv1alpha1.MembersReadyCondition,
+	securityv1alpha1.QuotaReadyCondition,
+	securityv1alpha1.NetworkIsolationReadyCondition,
+	securityv1alpha1.PodSecurityReadyCondition,
+	securityv1alpha1.AutomationAccountsReadyCondition,
+}
+
+// setCondition sets the given condition on the organization status, stamping
+// it with the organization generation.
+func setCondition(organization *securityv1alpha1.Organization, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&organization.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: organization.Generation,
+	})
+}
+
+// setReadyCondition summarizes the readiness conditions into the Ready
+// condition. Suspended organizations are never ready.
+func setReadyCondition(organization *securityv1alpha1.Organization) {
+	if organization.Spec.Suspended {
+		setCondition(organization, securityv1alpha1.ReadyCondition, metav1.ConditionFalse, reasonSuspended,
+			"Organization is suspended")
+		return
+	}
+
+	var notReady []string
+	for _, conditionType := range readinessConditions {
+		condition := meta.FindStatusCondition(organization.Status.Conditions, conditionType)
+		if condition != nil && condition.Status != metav1.ConditionTrue {
+			notReady = append(notReady, conditionType)
+		}
+	}
+
+	if len(notReady) > 0 {
+		setCondition(organization, securityv1alpha1.ReadyCondition, metav1.ConditionFalse, reasonNotReady,
+			"Not ready: "+strings.Join(notReady, ", "))
+		return
+	}
+	setCondition(organization, securityv1alpha1.ReadyCondition, metav1.ConditionTrue, reasonReconciled,
+		"All managed objects are reconciled")
+}
+
+// setPhase summarizes the lifecycle of the organization into its phase. The
+// phase of terminating organizations is set by reconcileDelete.
+func setPhase(organization *securityv1alpha1.Organization, reconcileErr error) {
+	namespaceReady := meta.FindStatusCondition(organization.Status.Conditions, securityv1alpha1.NamespaceReadyCondition)
+	switch {
+	case reconcileErr != nil || (namespaceReady != nil && namespaceReady.Status != metav1.ConditionTrue):
+		organization.Status.Phase = securityv1alpha1.OrganizationPhaseFailed
+	case namespaceReady != nil:
+		organization.Status.Phase = securityv1alpha1.OrganizationPhaseActive
+	default:
+		organization.Status.Phase = securityv1alpha1.OrganizationPhasePending
+	}
+}
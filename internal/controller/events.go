@@ -0,0 +1,62 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// Event reasons recorded on organizations by the Organization controller.
+// Failures of the objects managed for an organization are recorded as Warning
+// events with the reason of the condition reporting them, such as
+// MembersReconcileFailed, see recordConditionFailure.
+const (
+	eventReasonNamespaceCreated           = "NamespaceCreated"
+	eventReasonNamespaceDriftRepaired     = "NamespaceDriftRepaired"
+	eventReasonNamespaceAdopted           = "NamespaceAdopted"
+	eventReasonNamespaceOwnershipConflict = "NamespaceOwnershipConflict"
+	eventReasonFinalizerMigrated          = "FinalizerMigrated"
+	eventReasonFinalizerAdded             = "FinalizerAdded"
+	eventReasonDeletionStarted            = "DeletionStarted"
+	eventReasonDeletionCompleted          = "DeletionCompleted"
+	eventReasonDeletionFailed             = "DeletionFailed"
+	eventReasonDeletionStuck              = "DeletionStuck"
+	eventReasonDeletionStalled            = "DeletionStalled"
+	eventReasonReconcileFailed            = "ReconcileFailed"
+)
+
+// recordFailure records a Warning event with the given reason for an error
+// reconciling the organization.
+func (r *OrganizationReconciler) recordFailure(organization *securityv1alpha1.Organization, reason string, err error) {
+	r.Recorder.Event(organization, corev1.EventTypeWarning, reason, err.Error())
+}
+
+// recordConditionFailure records a Warning event for an error reconciling
+// the objects reported by the given condition, with the reason of the
+// condition if it reports the failure.
+func (r *OrganizationReconciler) recordConditionFailure(organization *securityv1alpha1.Organization, conditionType string, err error) {
+	reason := eventReasonReconcileFailed
+	condition := meta.FindStatusCondition(organization.Status.Conditions, conditionType)
+	if condition != nil && condition.Status == metav1.ConditionFalse {
+		reason = condition.Reason
+	}
+	r.recordFailure(organization, reason, err)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// patchFinalizers applies mutate to the finalizers of the organization and
+// patches them when it reports a change. The patch carries the resource
+// version so that finalizers changed concurrently are not overwritten; on
+// conflicts the organization is read again and the change is retried.
+func patchFinalizers(ctx context.Context, c client.Client, organization *securityv1alpha1.Organization,
+	mutate func(*securityv1alpha1.Organization) bool) (bool, error) {
+	var changed, retried bool
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if retried {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(organization), organization); err != nil {
+				return err
+			}
+		}
+		retried = true
+
+		patch := client.MergeFromWithOptions(organization.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		changed = mutate(organization)
+		if !changed {
+			return nil
+		}
+		return c.Patch(ctx, organization, patch)
+	})
+	return changed, err
+}
+
+// migrateFinalizers adds the finalizer to a live organization, replacing the
+// legacy finalizer of the operatorkit based operator. Organizations being
+// deleted are left to reconcileDelete, as no finalizers can be added to them.
+// It reports whether the finalizers were patched and whether the patch
+// replaced the legacy finalizer, as seen by the attempt that was patched
+// rather than by the organization it was given.
+func migrateFinalizers(ctx context.Context, c client.Client, organization *securityv1alpha1.Organization) (changed, replaced bool, err error) {
+	changed, err = patchFinalizers(ctx, c, organization, func(organization *securityv1alpha1.Organization) bool {
+		if organization.DeletionTimestamp != nil {
+			replaced = false
+			return false
+		}
+		replaced = controllerutil.RemoveFinalizer(organization, oldFinalizer)
+		added := controllerutil.AddFinalizer(organization, newFinalizer)
+		return replaced || added
+	})
+	if err != nil {
+		return false, false, err
+	}
+	return changed, replaced, nil
+}
+
+// removeFinalizers removes the finalizer and the legacy finalizer from the
+// organization.
+func removeFinalizers(organization *securityv1alpha1.Organization) bool {
+	removedOld := controllerutil.RemoveFinalizer(organization, oldFinalizer)
+	removedNew := controllerutil.RemoveFinalizer(organization, newFinalizer)
+	return removedOld || removedNew
+}
+
+// FinalizerMigration migrates the finalizers of all organizations once when
+// the manager starts, so that organizations that are not reconciled soon
+// after an upgrade do not keep the legacy finalizer.
+type FinalizerMigration struct {
+	Client   client.Client
+	Recorder record.EventRecorder
+}
+
+var _ manager.LeaderElectionRunnable = &FinalizerMigration{}
+
+// Start implements manager.Runnable. Failures are logged and left to the
+// reconciliation of the organizations instead of stopping the manager.
+func (m *FinalizerMigration) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("finalizer-migration")
+
+	migrated, err := MigrateFinalizers(ctx, m.Client)
+	var replaced int
+	for _, organization := range migrated {
+		if organization.Legacy {
+			replaced++
+			logger.Info("Replaced legacy finalizer of Organization", "organization", organization.Name)
+			m.Recorder.Eventf(organization.Organization, corev1.EventTypeNormal, eventReasonFinalizerMigrated,
+				"Replaced legacy finalizer %s with %s", oldFinalizer, newFinalizer)
+			continue
+		}
+		logger.Info("Added missing finalizer to Organization", "organization", organization.Name)
+		m.Recorder.Eventf(organization.Organization, corev1.EventTypeNormal, eventReasonFinalizerAdded,
+			"Added missing finalizer %s", newFinalizer)
+	}
+	if err != nil {
+		logger.Error(err, "Failed to migrate Organization finalizers", "replaced", replaced, "added", len(migrated)-replaced)
+		return nil
+	}
+	logger.Info("Finalizer migration completed", "replaced", replaced, "added", len(migrated)-replaced)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (m *FinalizerMigration) NeedLeaderElection() bool {
+	return true
+}
+
+// MigratedOrganization is an organization whose finalizers were migrated by
+// MigrateFinalizers.
+type MigratedOrganization struct {
+	*securityv1alpha1.Organization
+	// Legacy reports whether the legacy finalizer was replaced. Otherwise the
+	// organization had neither finalizer and the finalizer was only added.
+	Legacy bool
+}
+
+// MigrateFinalizers migrates the finalizers of all live organizations and
+// returns the organizations it changed.
+func MigrateFinalizers(ctx context.Context, c client.Client) ([]MigratedOrganization, error) {
+	var organizationList securityv1alpha1.OrganizationList
+	if err := c.List(ctx, &organizationList); err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	var migrated []MigratedOrganization
+	var errs []error
+	for i := range organizationList.Items {
+		organization := &organizationList.Items[i]
+		changed, replaced, err := migrateFinalizers(ctx, c, organization)
+		if client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("failed to migrate finalizers of Organization %s: %w", organization.Name, err))
+			continue
+		}
+		if changed {
+			migrated = append(migrated, MigratedOrganization{Organization: organization, Legacy: replaced})
+		}
+	}
+	return migrated, errors.Join(errs...)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Organization finalizers", func() {
+	Context("When migrating the finalizers at startup", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			deletionTimestamp := metav1.Now()
+			fakeClient = newFakeClient(
+				&securityv1alpha1.Organization{ObjectMeta: metav1.ObjectMeta{
+					Name:       "legacy",
+					Finalizers: []string{oldFinalizer, "example.com/cleanup"},
+				}},
+				&securityv1alpha1.Organization{ObjectMeta: metav1.ObjectMeta{
+					Name: "unfinalized",
+				}},
+				&securityv1alpha1.Organization{ObjectMeta: metav1.ObjectMeta{
+					Name:       "migrated",
+					Finalizers: []string{newFinalizer},
+				}},
+				&securityv1alpha1.Organization{ObjectMeta: metav1.ObjectMeta{
+					Name:              "deleting",
+					DeletionTimestamp: &deletionTimestamp,
+					Finalizers:        []string{oldFinalizer},
+				}},
+			)
+		})
+
+		It("Should replace the legacy finalizer of live organizations", func() {
+			migrated, err := MigrateFinalizers(ctx, fakeClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(migrated).To(HaveLen(2))
+			Expect(migrated[0].Name).To(Equal("legacy"))
+			Expect(migrated[0].Legacy).To(BeTrue())
+			Expect(migrated[1].Name).To(Equal("unfinalized"))
+			Expect(migrated[1].Legacy).To(BeFalse())
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "legacy"}, org)).To(Succeed())
+			Expect(org.Finalizers).To(ConsistOf("example.com/cleanup", newFinalizer))
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "unfinalized"}, org)).To(Succeed())
+			Expect(org.Finalizers).To(ConsistOf(newFinalizer))
+		})
+
+		It("Should leave organizations being deleted to their reconciliation", func() {
+			_, err := MigrateFinalizers(ctx, fakeClient)
+			Expect(err).NotTo(HaveOccurred())
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "deleting"}, org)).To(Succeed())
+			Expect(org.Finalizers).To(ConsistOf(oldFinalizer))
+		})
+
+		It("Should not change migrated organizations again", func() {
+			_, err := MigrateFinalizers(ctx, fakeClient)
+			Expect(err).NotTo(HaveOccurred())
+
+			migrated, err := MigrateFinalizers(ctx, fakeClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(migrated).To(BeEmpty())
+		})
+
+		It("Should record replaced and added finalizers as different events", func() {
+			recorder := record.NewFakeRecorder(10)
+			migration := &FinalizerMigration{Client: fakeClient, Recorder: recorder}
+			Expect(migration.Start(ctx)).To(Succeed())
+
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(Equal("Normal FinalizerMigrated Replaced legacy finalizer " + oldFinalizer + " with " + newFinalizer))
+			Expect(<-recorder.Events).To(Equal("Normal FinalizerAdded Added missing finalizer " + newFinalizer))
+		})
+	})
+
+	Context("When the finalizers are changed concurrently", func() {
+		It("Should retry the patch with the latest finalizers", func() {
+			ctx := context.Background()
+			conflicts := 0
+			fakeClient := interceptor.NewClient(newFakeClient(
+				&securityv1alpha1.Organization{ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-conflict",
+					Finalizers: []string{oldFinalizer},
+				}},
+			).(client.WithWatch), interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					if conflicts == 0 {
+						conflicts++
+						// Another writer adds a finalizer before the patch is applied.
+						current := &securityv1alpha1.Organization{}
+						Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), current)).To(Succeed())
+						current.Finalizers = append(current.Finalizers, "example.com/cleanup")
+						Expect(c.Update(ctx, current)).To(Succeed())
+						return errors.NewConflict(schema.GroupResource{Resource: "organizations"}, obj.GetName(), nil)
+					}
+					return c.Patch(ctx, obj, patch, opts...)
+				},
+			})
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-conflict"}, org)).To(Succeed())
+			changed, replaced, err := migrateFinalizers(ctx, fakeClient, org)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(replaced).To(BeTrue())
+			Expect(conflicts).To(Equal(1))
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-conflict"}, org)).To(Succeed())
+			Expect(org.Finalizers).To(ConsistOf("example.com/cleanup", newFinalizer))
+		})
+
+		It("Should not report a replacement made by another writer", func() {
+			ctx := context.Background()
+			conflicts := 0
+			fakeClient := interceptor.NewClient(newFakeClient(
+				&securityv1alpha1.Organization{ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-conflict",
+					Finalizers: []string{oldFinalizer},
+				}},
+			).(client.WithWatch), interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					if conflicts == 0 {
+						conflicts++
+						// The startup migration replaces the legacy finalizer first.
+						current := &securityv1alpha1.Organization{}
+						Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), current)).To(Succeed())
+						current.Finalizers = []string{newFinalizer}
+						Expect(c.Update(ctx, current)).To(Succeed())
+						return errors.NewConflict(schema.GroupResource{Resource: "organizations"}, obj.GetName(), nil)
+					}
+					return c.Patch(ctx, obj, patch, opts...)
+				},
+			})
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-conflict"}, org)).To(Succeed())
+			changed, replaced, err := migrateFinalizers(ctx, fakeClient, org)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeFalse())
+			Expect(replaced).To(BeFalse())
+			Expect(conflicts).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,80 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// inventoryResource is a namespaced resource counted in organization namespaces.
+type inventoryResource struct {
+	resource string
+	gvk      schema.GroupVersionKind
+}
+
+// inventoryResources are the resources counted in organization namespaces.
+var inventoryResources = []inventoryResource{
+	{resource: "configmaps", gvk: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}},
+	{resource: "persistentvolumeclaims", gvk: schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}},
+	{resource: "pods", gvk: schema.GroupVersionKind{Version: "v1", Kind: "Pod"}},
+	{resource: "secrets", gvk: schema.GroupVersionKind{Version: "v1", Kind: "Secret"}},
+	{resource: "serviceaccounts", gvk: schema.GroupVersionKind{Version: "v1", Kind: "ServiceAccount"}},
+	{resource: "services", gvk: schema.GroupVersionKind{Version: "v1", Kind: "Service"}},
+	{resource: "daemonsets.apps", gvk: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}},
+	{resource: "deployments.apps", gvk: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}},
+	{resource: "statefulsets.apps", gvk: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}},
+	{resource: "cronjobs.batch", gvk: schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"}},
+	{resource: "jobs.batch", gvk: schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}},
+	{resource: "ingresses.networking.k8s.io", gvk: schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}},
+	{resource: "networkpolicies.networking.k8s.io", gvk: schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}},
+	{resource: "rolebindings.rbac.authorization.k8s.io", gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"}},
+	{resource: "roles.rbac.authorization.k8s.io", gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"}},
+}
+
+// inventoryNamespace counts the objects in the namespace by resource.
+// Resources without objects are omitted. Only object metadata is read, and it
+// is read from the API server so that no informers are started for the
+// counted resources.
+func (r *OrganizationReconciler) inventoryNamespace(ctx context.Context, namespace string) (map[string]int32, error) {
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+
+	inventory := map[string]int32{}
+	for _, resource := range inventoryResources {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(resource.gvk.GroupVersion().WithKind(resource.gvk.Kind + "List"))
+		if err := reader.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s in namespace %s: %w", resource.resource, namespace, err)
+		}
+		if len(list.Items) > 0 {
+			inventory[resource.resource] = int32(len(list.Items)) //nolint:gosec
+		}
+	}
+
+	return inventory, nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+const (
+	// memberRoleLabel is set on the member RoleBindings to the member role they grant.
+	memberRoleLabel = securityv1alpha1.ReservedKeyPrefix + "member-role"
+
+	memberRoleBindingPrefix = "organization-member-"
+)
+
+// DefaultMemberRoles maps the member roles to the ClusterRoles Kubernetes
+// ships by default.
+var DefaultMemberRoles = map[string]string{
+	"admin":  "admin",
+	"editor": "edit",
+	"viewer": "view",
+}
+
+// ParseMemberRoles parses a comma separated list of role=ClusterRole pairs.
+func ParseMemberRoles(text string) (map[string]string, error) {
+	roles := map[string]string{}
+	for _, pair := range strings.Split(text, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		role, clusterRole, ok := strings.Cut(pair, "=")
+		role, clusterRole = strings.TrimSpace(role), strings.TrimSpace(clusterRole)
+		if !ok || clusterRole == "" {
+			return nil, fmt.Errorf("member role %q must have the form role=ClusterRole", pair)
+		}
+		if errs := validation.IsDNS1123Label(role); len(errs) > 0 {
+			return nil, fmt.Errorf("member role %q is invalid: %s", role, strings.Join(errs, ", "))
+		}
+		if _, ok := roles[role]; ok {
+			return nil, fmt.Errorf("member role %q is defined more than once", role)
+		}
+		roles[role] = clusterRole
+	}
+	return roles, nil
+}
+
+// FormatMemberRoles formats the member roles as accepted by ParseMemberRoles.
+func FormatMemberRoles(roles map[string]string) string {
+	pairs := make([]string, 0, len(roles))
+	for role, clusterRole := range roles {
+		pairs = append(pairs, role+"="+clusterRole)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// reconcileMembers maintains one RoleBinding per member role in the
+// organization namespace, binding the members with that role to the
+// ClusterRole configured for it. RoleBindings of roles without members are
+// pruned. Members of every configured role are granted read access to the
+// organization itself.
+// The outcome is recorded in the MembersReady condition.
+func (r *OrganizationReconciler) reconcileMembers(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	roles := r.MemberRoles
+	if roles == nil {
+		roles = DefaultMemberRoles
+	}
+
+	subjects := map[string][]rbacv1.Subject{}
+	var accessSubjects []rbacv1.Subject
+	var unknownRoles []string
+	for _, member := range organization.Spec.Members {
+		if _, ok := roles[member.Role]; !ok {
+			unknownRoles = append(unknownRoles, member.Role)
+			continue
+		}
+		subject := memberSubject(member, organization.Status.Namespace)
+		subjects[member.Role] = append(subjects[member.Role], subject)
+		accessSubjects = append(accessSubjects, subject)
+	}
+
+	if err := r.ensureMemberRoleBindings(ctx, organization, roles, subjects); err != nil {
+		setCondition(organization, securityv1alpha1.MembersReadyCondition, metav1.ConditionFalse, reasonMembersReconcileFailed, err.Error())
+		return err
+	}
+	if err := r.ensureOrganizationAccess(ctx, organization, accessSubjects); err != nil {
+		setCondition(organization, securityv1alpha1.MembersReadyCondition, metav1.ConditionFalse, reasonMembersReconcileFailed, err.Error())
+		return err
+	}
+
+	if len(unknownRoles) > 0 {
+		setCondition(organization, securityv1alpha1.MembersReadyCondition, metav1.ConditionFalse, reasonUnknownMemberRole,
+			fmt.Sprintf("Unknown member roles: %s", strings.Join(unknownRoles, ", ")))
+		return nil
+	}
+	setCondition(organization, securityv1alpha1.MembersReadyCondition, metav1.ConditionTrue, reasonMembersReconciled,
+		fmt.Sprintf("RoleBindings of %d members are reconciled", len(organization.Spec.Members)))
+	return nil
+}
+
+// ensureMemberRoleBindings creates or updates the RoleBindings of the given
+// subjects by role, and deletes the RoleBindings of the other roles.
+func (r *OrganizationReconciler) ensureMemberRoleBindings(ctx context.Context, organization *securityv1alpha1.Organization, roles map[string]string, subjects map[string][]rbacv1.Subject) error {
+	namespace := organization.Status.Namespace
+
+	memberRoles := make([]string, 0, len(subjects))
+	for role := range subjects {
+		memberRoles = append(memberRoles, role)
+	}
+	sort.Strings(memberRoles)
+
+	for _, role := range memberRoles {
+		roleRef := rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     roles[role],
+		}
+		err := r.ensureRoleBinding(ctx, organization, memberRoleBindingPrefix+role, map[string]string{memberRoleLabel: role}, roleRef, subjects[role])
+		if err != nil {
+			return err
+		}
+	}
+
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err := r.List(ctx, roleBindings, client.InNamespace(namespace), client.HasLabels{memberRoleLabel},
+		client.MatchingLabels{securityv1alpha1.OrganizationLabel: organization.Name}); err != nil {
+		return fmt.Errorf("failed to list RoleBindings: %w", err)
+	}
+	for i := range roleBindings.Items {
+		roleBinding := &roleBindings.Items[i]
+		if _, ok := subjects[roleBinding.Labels[memberRoleLabel]]; ok || !metav1.IsControlledBy(roleBinding, organization) {
+			continue
+		}
+		if err := r.Delete(ctx, roleBinding); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete RoleBinding %s: %w", roleBinding.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ensureRoleBinding creates or updates the RoleBinding in the organization
+// namespace. RoleBindings referencing another role are recreated, as the role
+// reference of a RoleBinding is immutable. Existing RoleBindings that are not
+// controlled by the organization are refused. The given labels identify the
+// RoleBinding in addition to the organization labels.
+func (r *OrganizationReconciler) ensureRoleBinding(ctx context.Context, organization *securityv1alpha1.Organization, name string, labels map[string]string, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) error {
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: organization.Status.Namespace,
+		},
+	}
+
+	err := r.Get(ctx, client.ObjectKeyFromObject(roleBinding), roleBinding)
+	if err == nil && !metav1.IsControlledBy(roleBinding, organization) {
+		return fmt.Errorf("RoleBinding %s exists and is not controlled by the organization", roleBinding.Name)
+	}
+	if err == nil && roleBinding.RoleRef != roleRef {
+		if err := r.Delete(ctx, roleBinding); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete RoleBinding %s: %w", roleBinding.Name, err)
+		}
+		roleBinding = &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: organization.Status.Namespace}}
+	} else if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get RoleBinding %s: %w", roleBinding.Name, err)
+	}
+
+	_, err = r.ensureObject(ctx, roleBinding, func() error {
+		roleBindingLabels := childLabels(roleBinding.GetLabels(), organization)
+		for key, value := range labels {
+			roleBindingLabels[key] = value
+		}
+		roleBinding.SetLabels(roleBindingLabels)
+		roleBinding.RoleRef = roleRef
+		roleBinding.Subjects = subjects
+		return ctrl.SetControllerReference(organization, roleBinding, r.Scheme)
+	})
+	return err
+}
+
+// memberSubject returns the RBAC subject of the member. Service accounts
+// without namespace default to the organization namespace.
+func memberSubject(member securityv1alpha1.OrganizationMember, namespace string) rbacv1.Subject {
+	switch member.Kind {
+	case securityv1alpha1.MemberKindServiceAccount:
+		if member.Namespace != "" {
+			namespace = member.Namespace
+		}
+		return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: member.Name, Namespace: namespace}
+	case securityv1alpha1.MemberKindGroup:
+		return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: member.Name}
+	default:
+		return rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: member.Name}
+	}
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Organization members", func() {
+	DescribeTable("Parsing member roles",
+		func(text string, expected map[string]string) {
+			roles, err := ParseMemberRoles(text)
+			if expected == nil {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(roles).To(Equal(expected))
+			}
+		},
+		Entry("default roles", FormatMemberRoles(DefaultMemberRoles), DefaultMemberRoles),
+		Entry("custom roles", "owner=cluster-admin, auditor=view", map[string]string{"owner": "cluster-admin", "auditor": "view"}),
+		Entry("missing ClusterRole", "admin", nil),
+		Entry("invalid role", "Admin=admin", nil),
+		Entry("duplicate role", "admin=admin,admin=edit", nil),
+	)
+
+	Context("When reconciling members", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			reconciler *OrganizationReconciler
+		)
+
+		reconcileOrganization := func() error {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-members"},
+			})
+			return err
+		}
+
+		updateMembers := func(members ...securityv1alpha1.OrganizationMember) {
+			org := &securityv1alpha1.Organization{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "test-members"}, org)).To(Succeed())
+			org.Spec.Members = members
+			ExpectWithOffset(1, fakeClient.Update(ctx, org)).To(Succeed())
+		}
+
+		membersReady := func() *metav1.Condition {
+			org := &securityv1alpha1.Organization{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "test-members"}, org)).To(Succeed())
+			return meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.MembersReadyCondition)
+		}
+
+		admins := []securityv1alpha1.OrganizationMember{
+			{Kind: securityv1alpha1.MemberKindGroup, Name: "customer-admins", Role: "admin"},
+			{Kind: securityv1alpha1.MemberKindServiceAccount, Name: "ci", Role: "admin"},
+		}
+		viewer := securityv1alpha1.OrganizationMember{Kind: securityv1alpha1.MemberKindUser, Name: "jane@example.com", Role: "viewer"}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			fakeClient = newFakeClient()
+			reconciler = &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+			Expect(fakeClient.Create(ctx, &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-members"},
+				Spec: securityv1alpha1.OrganizationSpec{
+					Members: append(append([]securityv1alpha1.OrganizationMember{}, admins...), viewer),
+				},
+			})).To(Succeed())
+		})
+
+		Context("With members of known roles", func() {
+			BeforeEach(func() {
+				Expect(reconcileOrganization()).To(Succeed())
+			})
+
+			It("Should bind the members of each role to its ClusterRole", func() {
+				adminBinding := &rbacv1.RoleBinding{}
+				Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-members", Name: "organization-member-admin"}, adminBinding)).To(Succeed())
+				Expect(adminBinding.RoleRef.Name).To(Equal("admin"))
+				Expect(adminBinding.Subjects).To(Equal([]rbacv1.Subject{
+					{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "customer-admins"},
+					{Kind: rbacv1.ServiceAccountKind, Name: "ci", Namespace: "org-test-members"},
+				}))
+				Expect(metav1.GetControllerOf(adminBinding)).NotTo(BeNil())
+
+				viewerBinding := &rbacv1.RoleBinding{}
+				Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-members", Name: "organization-member-viewer"}, viewerBinding)).To(Succeed())
+				Expect(viewerBinding.RoleRef.Name).To(Equal("view"))
+				Expect(viewerBinding.Subjects).To(Equal([]rbacv1.Subject{
+					{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "jane@example.com"},
+				}))
+			})
+
+			It("Should report the members as ready", func() {
+				condition := membersReady()
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Reason).To(Equal("MembersReconciled"))
+			})
+
+			It("Should prune the RoleBindings of roles without members", func() {
+				updateMembers(admins...)
+				Expect(reconcileOrganization()).To(Succeed())
+
+				err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-members", Name: "organization-member-viewer"}, &rbacv1.RoleBinding{})
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+				Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-members", Name: "organization-member-admin"}, &rbacv1.RoleBinding{})).To(Succeed())
+			})
+
+			It("Should recreate the RoleBinding of a role mapped to another ClusterRole", func() {
+				reconciler.MemberRoles = map[string]string{"admin": "cluster-admin", "viewer": "view"}
+				Expect(reconcileOrganization()).To(Succeed())
+
+				adminBinding := &rbacv1.RoleBinding{}
+				Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-members", Name: "organization-member-admin"}, adminBinding)).To(Succeed())
+				Expect(adminBinding.RoleRef.Name).To(Equal("cluster-admin"))
+				Expect(adminBinding.Subjects).To(HaveLen(2))
+			})
+		})
+
+		It("Should report members with a role unknown to the operator", func() {
+			updateMembers(append(append([]securityv1alpha1.OrganizationMember{}, admins...),
+				securityv1alpha1.OrganizationMember{Kind: securityv1alpha1.MemberKindUser, Name: "john@example.com", Role: "owner"})...)
+			Expect(reconcileOrganization()).To(Succeed())
+
+			condition := membersReady()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("UnknownMemberRole"))
+			Expect(condition.Message).To(ContainSubstring("owner"))
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-members", Name: "organization-member-admin"}, &rbacv1.RoleBinding{})).To(Succeed())
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-members", Name: "organization-member-owner"}, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-members"}, org)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(org.Status.Conditions, securityv1alpha1.ReadyCondition)).To(BeTrue())
+		})
+
+		It("Should refuse a member RoleBinding not controlled by the Organization", func() {
+			Expect(fakeClient.Create(ctx, &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-members", Name: "organization-member-viewer"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "editors"}},
+			})).To(Succeed())
+			Expect(reconcileOrganization()).To(MatchError(ContainSubstring("RoleBinding organization-member-viewer exists and is not controlled by the organization")))
+
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-members", Name: "organization-member-viewer"}, roleBinding)).To(Succeed())
+			Expect(roleBinding.OwnerReferences).To(BeEmpty())
+			Expect(roleBinding.RoleRef.Name).To(Equal("edit"))
+			Expect(roleBinding.Subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "editors"}}))
+
+			condition := membersReady()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("MembersReconcileFailed"))
+		})
+	})
+
+	Context("When granting the members read access to the Organization", func() {
+		It("Should grant the members read access to the Organization and revoke it on deletion", func() {
+			ctx := context.Background()
+			fakeClient := newFakeClient()
+			reconciler := &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-access"},
+				Spec: securityv1alpha1.OrganizationSpec{
+					Members: []securityv1alpha1.OrganizationMember{
+						{Kind: securityv1alpha1.MemberKindGroup, Name: "customer-admins", Role: "admin"},
+						{Kind: securityv1alpha1.MemberKindGroup, Name: "customer-editors", Role: "editor"},
+						{Kind: securityv1alpha1.MemberKindUser, Name: "jane@example.com", Role: "viewer"},
+					},
+				},
+			}
+			Expect(fakeClient.Create(ctx, org)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-access"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterRole := &rbacv1.ClusterRole{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "organization-test-access-read"}, clusterRole)).To(Succeed())
+			Expect(clusterRole.Rules).To(Equal([]rbacv1.PolicyRule{{
+				APIGroups:     []string{"security.giantswarm.io"},
+				Resources:     []string{"organizations"},
+				ResourceNames: []string{"test-access"},
+				Verbs:         []string{"get", "watch"},
+			}}))
+
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "organization-test-access-read"}, clusterRoleBinding)).To(Succeed())
+			Expect(clusterRoleBinding.RoleRef.Name).To(Equal("organization-test-access-read"))
+			Expect(clusterRoleBinding.Subjects).To(Equal([]rbacv1.Subject{
+				{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "customer-admins"},
+				{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "customer-editors"},
+				{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "jane@example.com"},
+			}))
+
+			By("Deleting the organization")
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-access"}, org)).To(Succeed())
+			Expect(fakeClient.Delete(ctx, org)).To(Succeed())
+			for range 2 {
+				_, err = reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "test-access"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(errors.IsNotFound(fakeClient.Get(ctx, client.ObjectKey{Name: "test-access"}, org))).To(BeTrue())
+			Expect(errors.IsNotFound(fakeClient.Get(ctx, client.ObjectKey{Name: "organization-test-access-read"}, clusterRole))).To(BeTrue())
+			Expect(errors.IsNotFound(fakeClient.Get(ctx, client.ObjectKey{Name: "organization-test-access-read"}, clusterRoleBinding))).To(BeTrue())
+		})
+
+		It("Should refuse read access objects not controlled by the Organization", func() {
+			ctx := context.Background()
+			fakeClient := newFakeClient(&rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "organization-test-foreign-access-read"},
+				Rules: []rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"secrets"},
+					Verbs:     []string{"get"},
+				}},
+			})
+			reconciler := &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			Expect(fakeClient.Create(ctx, &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-foreign-access"},
+			})).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-foreign-access"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("ClusterRole organization-test-foreign-access-read exists and is not controlled by the organization")))
+
+			clusterRole := &rbacv1.ClusterRole{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "organization-test-foreign-access-read"}, clusterRole)).To(Succeed())
+			Expect(clusterRole.Rules[0].Resources).To(Equal([]string{"secrets"}))
+			Expect(metav1.GetControllerOf(clusterRole)).To(BeNil())
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-foreign-access"}, org)).To(Succeed())
+			membersReady := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.MembersReadyCondition)
+			Expect(membersReady).NotTo(BeNil())
+			Expect(membersReady.Status).To(Equal(metav1.ConditionFalse))
+			Expect(membersReady.Reason).To(Equal("MembersReconcileFailed"))
+		})
+
+		It("Should keep read access objects not controlled by the Organization on deletion", func() {
+			ctx := context.Background()
+			fakeClient := newFakeClient()
+			reconciler := &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-foreign-access"},
+			}
+			Expect(fakeClient.Create(ctx, org)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-foreign-access"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Replacing the ClusterRoleBinding with one managed by someone else")
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "organization-test-foreign-access-read"}, clusterRoleBinding)).To(Succeed())
+			Expect(fakeClient.Delete(ctx, clusterRoleBinding)).To(Succeed())
+			Expect(fakeClient.Create(ctx, &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "organization-test-foreign-access-read"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+			})).To(Succeed())
+
+			By("Deleting the organization")
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-foreign-access"}, org)).To(Succeed())
+			Expect(fakeClient.Delete(ctx, org)).To(Succeed())
+			for range 2 {
+				_, err = reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "test-foreign-access"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(errors.IsNotFound(fakeClient.Get(ctx, client.ObjectKey{Name: "test-foreign-access"}, org))).To(BeTrue())
+			Expect(errors.IsNotFound(fakeClient.Get(ctx, client.ObjectKey{Name: "organization-test-foreign-access-read"}, &rbacv1.ClusterRole{}))).To(BeTrue())
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "organization-test-foreign-access-read"}, clusterRoleBinding)).To(Succeed())
+			Expect(clusterRoleBinding.RoleRef.Name).To(Equal("view"))
+		})
+	})
+})
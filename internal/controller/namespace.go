@@ -0,0 +1,447 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+const (
+	// fieldManager is the field manager used for all writes of the operator.
+	fieldManager = "organization-operator"
+
+	managedByValue = securityv1alpha1.ManagedByValue
+
+	displayNameAnnotation      = securityv1alpha1.ReservedKeyPrefix + "display-name"
+	descriptionAnnotation      = securityv1alpha1.ReservedKeyPrefix + "description"
+	externalIDAnnotationPrefix = securityv1alpha1.ReservedKeyPrefix + "external-id."
+
+	// managedLabelsAnnotation and managedAnnotationsAnnotation record the
+	// label and annotation keys set by the operator on the namespace, so that
+	// keys dropped from the Organization can be removed again.
+	managedLabelsAnnotation      = securityv1alpha1.ReservedKeyPrefix + "managed-labels"
+	managedAnnotationsAnnotation = securityv1alpha1.ReservedKeyPrefix + "managed-annotations"
+	// managedChecksumAnnotation records a checksum of the metadata owned by
+	// the operator as it was last applied, so that changes made by others
+	// can be told apart from changes of the desired metadata.
+	managedChecksumAnnotation = securityv1alpha1.ReservedKeyPrefix + "managed-checksum"
+)
+
+// operationResultDriftRepaired is returned by ensureNamespace when metadata
+// owned by the operator was changed by someone else and has been restored.
+const operationResultDriftRepaired controllerutil.OperationResult = "driftRepaired"
+
+// reconcileNamespace creates or updates the organization namespace and
+// records the outcome in the NamespaceReady condition.
+func (r *OrganizationReconciler) reconcileNamespace(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	logger := log.FromContext(ctx)
+
+	namespaceName, err := r.namespaceName(ctx, organization)
+	if err != nil {
+		setCondition(organization, securityv1alpha1.NamespaceReadyCondition, metav1.ConditionFalse, reasonNamespaceReconcileFailed, err.Error())
+		r.recordFailure(organization, reasonNamespaceReconcileFailed, err)
+		return err
+	}
+
+	operationResult, err := r.ensureNamespace(ctx, organization, namespaceName)
+	if err != nil {
+		reason, ok := adoptionFailureReason(err)
+		if !ok {
+			reason = reasonNamespaceReconcileFailed
+		}
+		setCondition(organization, securityv1alpha1.NamespaceReadyCondition, metav1.ConditionFalse, reason, err.Error())
+		// Ownership conflicts are recorded by adoptNamespace together with
+		// the conflicting controller.
+		if reason != reasonNamespaceOwnershipConflict {
+			r.recordFailure(organization, reason, err)
+		}
+		return err
+	}
+
+	logger.Info("Namespace reconciled", "result", operationResult)
+
+	// The namespace is only recreated when someone else deleted it.
+	recreated := operationResult == controllerutil.OperationResultCreated && organization.Status.Namespace == namespaceName
+	if operationResult == controllerutil.OperationResultCreated && !recreated {
+		r.Recorder.Eventf(organization, corev1.EventTypeNormal, eventReasonNamespaceCreated,
+			"Created namespace %s", namespaceName)
+	}
+	if recreated || operationResult == operationResultDriftRepaired {
+		namespaceDriftRepairsTotal.Inc()
+		r.Recorder.Eventf(organization, corev1.EventTypeNormal, eventReasonNamespaceDriftRepaired,
+			"Repaired drift of operator managed metadata on namespace %s", namespaceName)
+	}
+
+	organization.Status.Namespace = namespaceName
+	setCondition(organization, securityv1alpha1.NamespaceReadyCondition, metav1.ConditionTrue, reasonNamespaceReconciled,
+		fmt.Sprintf("Namespace %s is reconciled", namespaceName))
+
+	return nil
+}
+
+// ensureNamespace creates the organization namespace, or merge patches the
+// metadata owned by the operator onto it. The patch only carries the keys the
+// operator changes, so labels and annotations set by other controllers or by
+// admins are never overwritten or removed. Existing namespaces that do not
+// belong to the organization are adopted or refused, see adoptNamespace.
+func (r *OrganizationReconciler) ensureNamespace(ctx context.Context, organization *securityv1alpha1.Organization, namespaceName string) (controllerutil.OperationResult, error) {
+	namespace := &corev1.Namespace{}
+	err := r.Get(ctx, client.ObjectKey{Name: namespaceName}, namespace)
+	if errors.IsNotFound(err) {
+		podSecurityLabels, err := r.podSecurityLabels(ctx, organization, nil)
+		if err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespaceName,
+			},
+		}
+		applyNamespaceMetadata(namespace, organization, podSecurityLabels)
+		if err := ctrl.SetControllerReference(organization, namespace, r.Scheme); err != nil {
+			return controllerutil.OperationResultNone, fmt.Errorf("unable to set controller reference on Namespace: %w", err)
+		}
+		if err := r.Create(ctx, namespace, client.FieldOwner(fieldManager)); err != nil {
+			return controllerutil.OperationResultNone, fmt.Errorf("failed to create Namespace: %w", err)
+		}
+		return controllerutil.OperationResultCreated, nil
+	}
+	if err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("failed to get Namespace: %w", err)
+	}
+
+	original := namespace.DeepCopy()
+	operationResult := controllerutil.OperationResultUpdated
+	if !ownsNamespace(namespace, organization) {
+		if err := r.adoptNamespace(ctx, organization, namespace); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		operationResult = operationResultAdopted
+	} else if namespaceDrifted(namespace) {
+		operationResult = operationResultDriftRepaired
+	}
+	if operationResult != operationResultAdopted && metav1.GetControllerOf(namespace) == nil {
+		if err := ctrl.SetControllerReference(organization, namespace, r.Scheme); err != nil {
+			return controllerutil.OperationResultNone, fmt.Errorf("unable to set controller reference on Namespace: %w", err)
+		}
+	}
+
+	podSecurityLabels, err := r.podSecurityLabels(ctx, organization, original)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	applyNamespaceMetadata(namespace, organization, podSecurityLabels)
+	if equality.Semantic.DeepEqual(original.ObjectMeta, namespace.ObjectMeta) {
+		return controllerutil.OperationResultNone, nil
+	}
+	if err := r.Patch(ctx, namespace, client.MergeFrom(original), client.FieldOwner(fieldManager)); err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("failed to patch Namespace: %w", err)
+	}
+	return operationResult, nil
+}
+
+// applyNamespaceMetadata merges the labels and annotations managed for the
+// organization, and the given Pod Security labels, onto the namespace.
+func applyNamespaceMetadata(namespace *corev1.Namespace, organization *securityv1alpha1.Organization, podSecurityLabels map[string]string) {
+	var managedLabels, managedAnnotations string
+	labels := namespace.GetLabels()
+	annotations := namespace.GetAnnotations()
+	if annotations != nil {
+		managedLabels = annotations[managedLabelsAnnotation]
+		managedAnnotations = annotations[managedAnnotationsAnnotation]
+	}
+
+	labels, managedLabels = mergeManagedMetadata(labels, managedLabels, desiredNamespaceLabels(organization, podSecurityLabels))
+	annotations, managedAnnotations = mergeManagedMetadata(annotations, managedAnnotations, desiredNamespaceAnnotations(organization))
+	annotations[managedLabelsAnnotation] = managedLabels
+	annotations[managedAnnotationsAnnotation] = managedAnnotations
+	delete(annotations, securityv1alpha1.ReleasedAnnotation)
+
+	namespace.SetLabels(labels)
+	namespace.SetAnnotations(annotations)
+	annotations[managedChecksumAnnotation] = managedMetadataChecksum(namespace)
+}
+
+// namespaceDrifted reports whether someone else changed the metadata owned by
+// the operator, or the controller reference, since the operator last applied
+// them. Namespaces without a recorded checksum are never reported.
+func namespaceDrifted(namespace *corev1.Namespace) bool {
+	checksum := namespace.GetAnnotations()[managedChecksumAnnotation]
+	return checksum != "" &&
+		(checksum != managedMetadataChecksum(namespace) || metav1.GetControllerOf(namespace) == nil)
+}
+
+// managedMetadataChecksum returns a checksum of the metadata owned by the
+// operator on the namespace, see managedMetadata.
+func managedMetadataChecksum(namespace *corev1.Namespace) string {
+	metadata := managedMetadata(namespace)
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, metadata[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// desiredNamespaceLabels returns the labels the operator manages on the
+// organization namespace. Labels set by the operator itself take precedence
+// over the ones requested in the Organization spec.
+func desiredNamespaceLabels(organization *securityv1alpha1.Organization, podSecurityLabels map[string]string) map[string]string {
+	labels := map[string]string{}
+	if organization.Spec.NamespaceMetadata != nil {
+		for key, value := range organization.Spec.NamespaceMetadata.Labels {
+			labels[key] = value
+		}
+	}
+	for key, value := range podSecurityLabels {
+		labels[key] = value
+	}
+
+	labels[securityv1alpha1.OrganizationLabel] = organization.Name
+	labels[securityv1alpha1.ManagedByLabel] = managedByValue
+	if organization.Spec.Suspended {
+		labels[securityv1alpha1.SuspendedLabel] = "true"
+	}
+
+	return labels
+}
+
+// desiredNamespaceAnnotations returns the annotations the operator manages on
+// the organization namespace. Annotations mirrored from the descriptive spec
+// fields take precedence over the ones requested in the Organization spec.
+func desiredNamespaceAnnotations(organization *securityv1alpha1.Organization) map[string]string {
+	annotations := map[string]string{}
+	if organization.Spec.NamespaceMetadata != nil {
+		for key, value := range organization.Spec.NamespaceMetadata.Annotations {
+			annotations[key] = value
+		}
+	}
+
+	if organization.Spec.DisplayName != "" {
+		annotations[displayNameAnnotation] = organization.Spec.DisplayName
+	}
+	if organization.Spec.Description != "" {
+		annotations[descriptionAnnotation] = organization.Spec.Description
+	}
+	for _, externalID := range organization.Spec.ExternalIDs {
+		annotations[externalIDAnnotationPrefix+externalID.System] = externalID.ID
+	}
+
+	return annotations
+}
+
+// mergeManagedMetadata sets the desired keys on current and removes the keys
+// listed in managed that are no longer desired. Keys owned by others are left
+// untouched. It returns the merged map and the new list of managed keys.
+func mergeManagedMetadata(current map[string]string, managed string, desired map[string]string) (map[string]string, string) {
+	if current == nil {
+		current = map[string]string{}
+	}
+
+	for _, key := range splitManagedKeys(managed) {
+		if _, ok := desired[key]; !ok {
+			delete(current, key)
+		}
+	}
+
+	keys := make([]string, 0, len(desired))
+	for key, value := range desired {
+		current[key] = value
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return current, strings.Join(keys, ",")
+}
+
+// splitManagedKeys parses the value of a managed keys annotation.
+func splitManagedKeys(managed string) []string {
+	if managed == "" {
+		return nil
+	}
+	return strings.Split(managed, ",")
+}
+
+// releasesNamespace reports whether the deletion policy of the organization
+// keeps its namespace in place.
+func releasesNamespace(organization *securityv1alpha1.Organization) bool {
+	return organization.Spec.DeletionPolicy == securityv1alpha1.DeletionPolicyRetain ||
+		organization.Spec.DeletionPolicy == securityv1alpha1.DeletionPolicyOrphan
+}
+
+// releaseNamespace removes the owner reference of the organization from its
+// namespace, so that the namespace is not garbage collected together with the
+// organization. With the Retain policy the labels and annotations managed by
+// the operator are removed as well, while Orphan keeps them.
+func (r *OrganizationReconciler) releaseNamespace(ctx context.Context, organization *securityv1alpha1.Organization, namespaceName string) error {
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: namespaceName}, namespace); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get Namespace: %w", err)
+	}
+
+	original := namespace.DeepCopy()
+	var ownerReferences []metav1.OwnerReference
+	for _, ownerReference := range namespace.OwnerReferences {
+		if ownerReference.UID != organization.UID {
+			ownerReferences = append(ownerReferences, ownerReference)
+		}
+	}
+	namespace.OwnerReferences = ownerReferences
+	if organization.Spec.DeletionPolicy == securityv1alpha1.DeletionPolicyRetain {
+		removeNamespaceMetadata(namespace)
+	} else {
+		metav1.SetMetaDataAnnotation(&namespace.ObjectMeta, securityv1alpha1.ReleasedAnnotation, organization.Name)
+	}
+
+	if equality.Semantic.DeepEqual(original.ObjectMeta, namespace.ObjectMeta) {
+		return nil
+	}
+	if err := r.Patch(ctx, namespace, client.MergeFrom(original), client.FieldOwner(fieldManager)); err != nil {
+		return fmt.Errorf("failed to patch Namespace: %w", err)
+	}
+	return nil
+}
+
+// removeNamespaceMetadata removes the labels and annotations managed by the
+// operator from the namespace.
+func removeNamespaceMetadata(namespace *corev1.Namespace) {
+	labels := namespace.GetLabels()
+	annotations := namespace.GetAnnotations()
+
+	for _, key := range splitManagedKeys(annotations[managedLabelsAnnotation]) {
+		delete(labels, key)
+	}
+	for _, key := range splitManagedKeys(annotations[managedAnnotationsAnnotation]) {
+		delete(annotations, key)
+	}
+	delete(labels, securityv1alpha1.OrganizationLabel)
+	delete(labels, securityv1alpha1.ManagedByLabel)
+	delete(annotations, managedLabelsAnnotation)
+	delete(annotations, managedAnnotationsAnnotation)
+	delete(annotations, managedChecksumAnnotation)
+
+	namespace.SetLabels(labels)
+	namespace.SetAnnotations(annotations)
+}
+
+// namespaceToOrganization maps a namespace event to the Organization managing
+// the namespace. The controller reference is preferred, the organization label
+// is used as fallback in case the reference has been removed.
+func namespaceToOrganization(_ context.Context, object client.Object) []reconcile.Request {
+	if owner := metav1.GetControllerOf(object); owner != nil && owner.Kind == "Organization" &&
+		strings.HasPrefix(owner.APIVersion, securityv1alpha1.GroupVersion.Group+"/") {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: owner.Name}}}
+	}
+	if name := object.GetLabels()[securityv1alpha1.OrganizationLabel]; name != "" && object.GetLabels()[securityv1alpha1.ManagedByLabel] == managedByValue {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
+	}
+	return nil
+}
+
+// namespaceDriftPredicate only lets through namespace events that touch the
+// metadata owned by the operator, as namespace metadata changes never bump
+// the namespace generation, and events reporting the progress of a namespace
+// deletion.
+func namespaceDriftPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !equality.Semantic.DeepEqual(managedMetadata(e.ObjectOld), managedMetadata(e.ObjectNew)) ||
+				!equality.Semantic.DeepEqual(e.ObjectOld.GetOwnerReferences(), e.ObjectNew.GetOwnerReferences()) ||
+				namespaceDeletionProgressed(e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// namespaceDeletionProgressed reports whether the deletion of the namespace
+// started, or its finalizers or remaining content conditions changed.
+func namespaceDeletionProgressed(oldObject, newObject client.Object) bool {
+	if newObject.GetDeletionTimestamp() == nil {
+		return false
+	}
+	if oldObject.GetDeletionTimestamp() == nil {
+		return true
+	}
+	oldNamespace, ok := oldObject.(*corev1.Namespace)
+	if !ok {
+		return false
+	}
+	newNamespace, ok := newObject.(*corev1.Namespace)
+	if !ok {
+		return false
+	}
+	return !equality.Semantic.DeepEqual(oldNamespace.Finalizers, newNamespace.Finalizers) ||
+		!equality.Semantic.DeepEqual(oldNamespace.Spec.Finalizers, newNamespace.Spec.Finalizers) ||
+		!equality.Semantic.DeepEqual(oldNamespace.Status.Conditions, newNamespace.Status.Conditions)
+}
+
+// managedMetadata returns the labels and annotations of the object that are
+// owned by the operator, including the annotations tracking them.
+func managedMetadata(object client.Object) map[string]string {
+	labels := object.GetLabels()
+	annotations := object.GetAnnotations()
+
+	metadata := map[string]string{
+		"annotation/" + managedLabelsAnnotation:       annotations[managedLabelsAnnotation],
+		"annotation/" + managedAnnotationsAnnotation:  annotations[managedAnnotationsAnnotation],
+		"label/" + securityv1alpha1.OrganizationLabel: labels[securityv1alpha1.OrganizationLabel],
+		"label/" + securityv1alpha1.ManagedByLabel:    labels[securityv1alpha1.ManagedByLabel],
+	}
+	for _, key := range splitManagedKeys(annotations[managedLabelsAnnotation]) {
+		metadata["label/"+key] = labels[key]
+	}
+	for _, key := range splitManagedKeys(annotations[managedAnnotationsAnnotation]) {
+		metadata["annotation/"+key] = annotations[key]
+	}
+	return metadata
+}
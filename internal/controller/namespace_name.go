@@ -0,0 +1,114 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// DefaultNamespaceNameTemplate renders the historical org-<name> namespace names.
+const DefaultNamespaceNameTemplate = "org-{{ .Name }}"
+
+// NamespaceNameTemplate renders the name of the namespace of an organization.
+type NamespaceNameTemplate struct {
+	template *template.Template
+}
+
+// namespaceNameData is the data the namespace name template is executed with.
+type namespaceNameData struct {
+	Name string
+}
+
+// NewNamespaceNameTemplate parses the given text/template and verifies that it
+// renders distinct, valid DNS-1123 labels for distinct organizations.
+func NewNamespaceNameTemplate(text string) (*NamespaceNameTemplate, error) {
+	tmpl, err := template.New("namespace").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse namespace name template: %w", err)
+	}
+	t := &NamespaceNameTemplate{template: tmpl}
+
+	first, err := t.render("example-a")
+	if err != nil {
+		return nil, err
+	}
+	second, err := t.render("example-b")
+	if err != nil {
+		return nil, err
+	}
+	if first == second {
+		return nil, fmt.Errorf("namespace name template %q must render distinct names for distinct organizations", text)
+	}
+
+	return t, nil
+}
+
+// NamespaceName returns the namespace name for the given organization.
+func (t *NamespaceNameTemplate) NamespaceName(organization *securityv1alpha1.Organization) (string, error) {
+	return t.render(organization.Name)
+}
+
+func (t *NamespaceNameTemplate) render(organizationName string) (string, error) {
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, namespaceNameData{Name: organizationName}); err != nil {
+		return "", fmt.Errorf("failed to render namespace name: %w", err)
+	}
+
+	name := buf.String()
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return "", fmt.Errorf("namespace name %q is not a valid DNS-1123 label: %s", name, strings.Join(errs, ", "))
+	}
+	return name, nil
+}
+
+// namespaceName resolves the namespace of the organization. Namespaces already
+// recorded in the status are kept, as namespaces cannot be renamed, so that
+// changing the template only affects new organizations. Organizations created
+// before the status was recorded keep their legacy org-<name> namespace.
+func (r *OrganizationReconciler) namespaceName(ctx context.Context, organization *securityv1alpha1.Organization) (string, error) {
+	if organization.Status.Namespace != "" {
+		return organization.Status.Namespace, nil
+	}
+
+	legacyName := fmt.Sprintf("org-%s", organization.Name)
+	namespace := &corev1.Namespace{}
+	err := r.Get(ctx, client.ObjectKey{Name: legacyName}, namespace)
+	if err == nil && securityv1alpha1.IsLegacyNamespace(namespace, organization) {
+		log.FromContext(ctx).Info("Keeping legacy namespace", "namespace", legacyName)
+		return legacyName, nil
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get Namespace: %w", err)
+	}
+
+	if r.NamespaceNameTemplate == nil {
+		return legacyName, nil
+	}
+	return r.NamespaceNameTemplate.NamespaceName(organization)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Namespace name template", func() {
+	DescribeTable("Validating templates",
+		func(text string, valid bool) {
+			_, err := NewNamespaceNameTemplate(text)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("default template", DefaultNamespaceNameTemplate, true),
+		Entry("suffix template", "{{ .Name }}-tenant", true),
+		Entry("unparsable template", "{{ .Name ", false),
+		Entry("unknown field", "{{ .Namespace }}", false),
+		Entry("invalid DNS-1123 label", "{{ .Name }}_tenant", false),
+		Entry("constant name", "tenant", false),
+	)
+
+	It("Should reject names longer than 63 characters", func() {
+		tmpl, err := NewNamespaceNameTemplate(DefaultNamespaceNameTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = tmpl.NamespaceName(&securityv1alpha1.Organization{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-very-long-organization-name-that-does-not-fit-into-a-label"},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("When reconciling with a custom template", func() {
+		It("Should use the template for new organizations and keep existing namespaces", func() {
+			ctx := context.Background()
+			fakeClient := newFakeClient()
+			tmpl, err := NewNamespaceNameTemplate("{{ .Name }}-tenant")
+			Expect(err).NotTo(HaveOccurred())
+
+			reconciler := &OrganizationReconciler{
+				Client:                fakeClient,
+				Scheme:                fakeClient.Scheme(),
+				Recorder:              &record.FakeRecorder{},
+				NamespaceNameTemplate: tmpl,
+			}
+
+			By("Creating a new organization")
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-template"},
+			}
+			Expect(fakeClient.Create(ctx, org)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-template"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-template-tenant"}, &corev1.Namespace{})).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-template"}, org)).To(Succeed())
+			Expect(org.Status.Namespace).To(Equal("test-template-tenant"))
+
+			By("Reconciling an organization with a legacy namespace and no recorded status")
+			legacyOrg := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-template-legacy"},
+			}
+			Expect(fakeClient.Create(ctx, legacyOrg)).To(Succeed())
+			legacyNamespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "org-test-template-legacy",
+					Labels: map[string]string{
+						"giantswarm.io/organization": "test-template-legacy",
+						"giantswarm.io/managed-by":   "organization-operator",
+					},
+				},
+			}
+			Expect(fakeClient.Create(ctx, legacyNamespace)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-template-legacy"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-template-legacy"}, legacyOrg)).To(Succeed())
+			Expect(legacyOrg.Status.Namespace).To(Equal("org-test-template-legacy"))
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-template-legacy-tenant"}, &corev1.Namespace{})).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+const (
+	denyIngressPolicyName       = "organization-default-deny-ingress"
+	allowOrganizationPolicyName = "organization-allow-same-organization"
+	allowPlatformPolicyName     = "organization-allow-platform"
+	allowPeersPolicyName        = "organization-allow-peers"
+)
+
+// reconcileNetworkIsolation maintains the NetworkPolicies isolating the
+// organization namespace: ingress is denied by default, and allowed from the
+// namespaces of the organization, from the platform namespaces and from the
+// namespaces of the allowed peers. The policies are removed when the
+// organization is not isolated. The outcome is recorded in the
+// NetworkIsolationReady condition.
+func (r *OrganizationReconciler) reconcileNetworkIsolation(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	isolated := r.networkIsolated(organization)
+
+	desired := map[string]*networkingv1.NetworkPolicyIngressRule{}
+	if isolated {
+		desired[denyIngressPolicyName] = nil
+		desired[allowOrganizationPolicyName] = namespaceLabelIngressRule(securityv1alpha1.OrganizationLabel, []string{organization.Name})
+		if len(r.PlatformNamespaces) > 0 {
+			desired[allowPlatformPolicyName] = namespaceLabelIngressRule(corev1.LabelMetadataName, r.PlatformNamespaces)
+		}
+		if len(organization.Spec.AllowedPeers) > 0 {
+			desired[allowPeersPolicyName] = namespaceLabelIngressRule(securityv1alpha1.OrganizationLabel, organization.Spec.AllowedPeers)
+		}
+	}
+
+	for _, name := range []string{denyIngressPolicyName, allowOrganizationPolicyName, allowPlatformPolicyName, allowPeersPolicyName} {
+		networkPolicy := &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: organization.Status.Namespace,
+			},
+		}
+
+		ingressRule, ok := desired[name]
+		if !ok {
+			if err := r.deleteObject(ctx, organization, networkPolicy); err != nil {
+				setCondition(organization, securityv1alpha1.NetworkIsolationReadyCondition, metav1.ConditionFalse, reasonNetworkIsolationReconcileFailed, err.Error())
+				return err
+			}
+			continue
+		}
+
+		_, err := r.ensureObject(ctx, networkPolicy, func() error {
+			networkPolicy.SetLabels(childLabels(networkPolicy.GetLabels(), organization))
+			networkPolicy.Spec = networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{},
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			}
+			if ingressRule != nil {
+				networkPolicy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{*ingressRule}
+			}
+			return ctrl.SetControllerReference(organization, networkPolicy, r.Scheme)
+		})
+		if err != nil {
+			setCondition(organization, securityv1alpha1.NetworkIsolationReadyCondition, metav1.ConditionFalse, reasonNetworkIsolationReconcileFailed, err.Error())
+			return err
+		}
+	}
+
+	if !isolated {
+		setCondition(organization, securityv1alpha1.NetworkIsolationReadyCondition, metav1.ConditionTrue, reasonNetworkIsolationDisabled,
+			"Network isolation is disabled")
+		return nil
+	}
+	setCondition(organization, securityv1alpha1.NetworkIsolationReadyCondition, metav1.ConditionTrue, reasonNetworkIsolationReconciled,
+		fmt.Sprintf("Namespace %s is isolated", organization.Status.Namespace))
+	return nil
+}
+
+// networkIsolated reports whether the organization namespace is isolated,
+// falling back to the operator default unless the organization overrides it.
+func (r *OrganizationReconciler) networkIsolated(organization *securityv1alpha1.Organization) bool {
+	switch organization.Spec.NetworkIsolation {
+	case securityv1alpha1.NetworkIsolationEnabled:
+		return true
+	case securityv1alpha1.NetworkIsolationDisabled:
+		return false
+	default:
+		return r.NetworkIsolation
+	}
+}
+
+// namespaceLabelIngressRule allows ingress from the namespaces with the given
+// label set to one of the values.
+func namespaceLabelIngressRule(key string, values []string) *networkingv1.NetworkPolicyIngressRule {
+	return &networkingv1.NetworkPolicyIngressRule{
+		From: []networkingv1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      key,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   values,
+				}},
+			},
+		}},
+	}
+}
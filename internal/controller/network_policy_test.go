@@ -0,0 +1,253 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Organization network isolation", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		reconciler *OrganizationReconciler
+	)
+
+	reconcileOrganization := func() error {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "test-isolated"},
+		})
+		return err
+	}
+
+	listNetworkPolicies := func() []string {
+		networkPolicies := &networkingv1.NetworkPolicyList{}
+		ExpectWithOffset(1, fakeClient.List(ctx, networkPolicies, client.InNamespace("org-test-isolated"))).To(Succeed())
+		var names []string
+		for _, networkPolicy := range networkPolicies.Items {
+			names = append(names, networkPolicy.Name)
+		}
+		return names
+	}
+
+	getNetworkPolicy := func(name string) *networkingv1.NetworkPolicy {
+		networkPolicy := &networkingv1.NetworkPolicy{}
+		ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-isolated", Name: name}, networkPolicy)).To(Succeed())
+		return networkPolicy
+	}
+
+	networkIsolationReady := func() *metav1.Condition {
+		org := &securityv1alpha1.Organization{}
+		ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "test-isolated"}, org)).To(Succeed())
+		return meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.NetworkIsolationReadyCondition)
+	}
+
+	updateOrganization := func(update func(*securityv1alpha1.Organization)) {
+		org := &securityv1alpha1.Organization{}
+		ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "test-isolated"}, org)).To(Succeed())
+		update(org)
+		ExpectWithOffset(1, fakeClient.Update(ctx, org)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fakeClient = newFakeClient()
+		reconciler = &OrganizationReconciler{
+			Client:             fakeClient,
+			Scheme:             fakeClient.Scheme(),
+			Recorder:           &record.FakeRecorder{},
+			NetworkIsolation:   true,
+			PlatformNamespaces: []string{"kube-system", "monitoring"},
+		}
+	})
+
+	Context("When network isolation is enabled for the operator", func() {
+		BeforeEach(func() {
+			Expect(fakeClient.Create(ctx, &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-isolated"},
+				Spec: securityv1alpha1.OrganizationSpec{
+					AllowedPeers: []string{"partner"},
+				},
+			})).To(Succeed())
+			Expect(reconcileOrganization()).To(Succeed())
+		})
+
+		It("Should deny ingress to the Namespace by default", func() {
+			Expect(listNetworkPolicies()).To(ConsistOf(
+				"organization-default-deny-ingress",
+				"organization-allow-same-organization",
+				"organization-allow-platform",
+				"organization-allow-peers",
+			))
+
+			denyIngress := getNetworkPolicy("organization-default-deny-ingress")
+			Expect(denyIngress.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress))
+			Expect(denyIngress.Spec.Ingress).To(BeEmpty())
+			Expect(metav1.GetControllerOf(denyIngress)).NotTo(BeNil())
+		})
+
+		It("Should allow ingress from the Organization and the platform namespaces", func() {
+			allowOrganization := getNetworkPolicy("organization-allow-same-organization")
+			Expect(allowOrganization.Spec.Ingress).To(HaveLen(1))
+			Expect(allowOrganization.Spec.Ingress[0].From[0].NamespaceSelector.MatchExpressions).To(Equal([]metav1.LabelSelectorRequirement{{
+				Key:      "giantswarm.io/organization",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{"test-isolated"},
+			}}))
+
+			allowPlatform := getNetworkPolicy("organization-allow-platform")
+			Expect(allowPlatform.Spec.Ingress).To(HaveLen(1))
+			Expect(allowPlatform.Spec.Ingress[0].From[0].NamespaceSelector.MatchExpressions).To(Equal([]metav1.LabelSelectorRequirement{{
+				Key:      "kubernetes.io/metadata.name",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{"kube-system", "monitoring"},
+			}}))
+		})
+
+		It("Should allow ingress from the allowed peers", func() {
+			allowPeers := getNetworkPolicy("organization-allow-peers")
+			Expect(allowPeers.Spec.Ingress).To(HaveLen(1))
+			Expect(allowPeers.Spec.Ingress[0].From[0].NamespaceSelector.MatchExpressions).To(Equal([]metav1.LabelSelectorRequirement{{
+				Key:      "giantswarm.io/organization",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{"partner"},
+			}}))
+		})
+
+		It("Should report the Namespace as isolated", func() {
+			condition := networkIsolationReady()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("NetworkIsolationReconciled"))
+		})
+
+		It("Should remove the peers policy once the peers are dropped", func() {
+			updateOrganization(func(org *securityv1alpha1.Organization) { org.Spec.AllowedPeers = nil })
+			Expect(reconcileOrganization()).To(Succeed())
+			Expect(listNetworkPolicies()).To(ConsistOf(
+				"organization-default-deny-ingress",
+				"organization-allow-same-organization",
+				"organization-allow-platform",
+			))
+		})
+
+		It("Should remove the policies once the Organization disables the isolation", func() {
+			updateOrganization(func(org *securityv1alpha1.Organization) {
+				org.Spec.NetworkIsolation = securityv1alpha1.NetworkIsolationDisabled
+			})
+			Expect(reconcileOrganization()).To(Succeed())
+			Expect(listNetworkPolicies()).To(BeEmpty())
+
+			condition := networkIsolationReady()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("NetworkIsolationDisabled"))
+		})
+	})
+
+	Context("When network isolation is disabled for the operator", func() {
+		BeforeEach(func() {
+			reconciler.NetworkIsolation = false
+			reconciler.PlatformNamespaces = nil
+		})
+
+		It("Should not isolate the Namespace by default", func() {
+			Expect(fakeClient.Create(ctx, &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-isolated"},
+			})).To(Succeed())
+			Expect(reconcileOrganization()).To(Succeed())
+			Expect(listNetworkPolicies()).To(BeEmpty())
+		})
+
+		It("Should isolate the Namespace when the Organization enables it", func() {
+			Expect(fakeClient.Create(ctx, &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-isolated"},
+				Spec: securityv1alpha1.OrganizationSpec{
+					NetworkIsolation: securityv1alpha1.NetworkIsolationEnabled,
+				},
+			})).To(Succeed())
+			Expect(reconcileOrganization()).To(Succeed())
+			Expect(listNetworkPolicies()).To(ConsistOf(
+				"organization-default-deny-ingress",
+				"organization-allow-same-organization",
+			))
+		})
+	})
+
+	Context("When a NetworkPolicy is not controlled by the Organization", func() {
+		BeforeEach(func() {
+			Expect(fakeClient.Create(ctx, &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "org-test-isolated",
+					Name:      "organization-allow-peers",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "v1",
+						Kind:       "ConfigMap",
+						Name:       "owner",
+						UID:        "owner-uid",
+						Controller: ptr.To(true),
+					}},
+				},
+				Spec: networkingv1.NetworkPolicySpec{
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+				},
+			})).To(Succeed())
+		})
+
+		It("Should refuse to take it over", func() {
+			Expect(fakeClient.Create(ctx, &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-isolated"},
+				Spec: securityv1alpha1.OrganizationSpec{
+					AllowedPeers: []string{"partner"},
+				},
+			})).To(Succeed())
+			Expect(reconcileOrganization()).To(MatchError(ContainSubstring("organization-allow-peers")))
+
+			networkPolicy := getNetworkPolicy("organization-allow-peers")
+			Expect(metav1.GetControllerOf(networkPolicy).Name).To(Equal("owner"))
+			Expect(networkPolicy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeEgress))
+
+			condition := networkIsolationReady()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("NetworkIsolationReconcileFailed"))
+		})
+
+		It("Should not delete it when it is not desired", func() {
+			Expect(fakeClient.Create(ctx, &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-isolated"},
+			})).To(Succeed())
+			Expect(reconcileOrganization()).To(Succeed())
+
+			Expect(listNetworkPolicies()).To(ContainElement("organization-allow-peers"))
+			Expect(metav1.GetControllerOf(getNetworkPolicy("organization-allow-peers")).Name).To(Equal("owner"))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// organizationAccessName returns the name of the ClusterRole and
+// ClusterRoleBinding granting the members read access to the organization.
+func organizationAccessName(organization *securityv1alpha1.Organization) string {
+	return fmt.Sprintf("organization-%s-read", organization.Name)
+}
+
+// ensureOrganizationAccess maintains a ClusterRole granting get and watch on
+// the organization itself, and a ClusterRoleBinding binding it to the given
+// subjects. Members need it because the Organization is cluster scoped, so
+// their RoleBindings in the organization namespace do not cover it. Existing
+// objects that are not controlled by the organization are refused.
+func (r *OrganizationReconciler) ensureOrganizationAccess(ctx context.Context, organization *securityv1alpha1.Organization, subjects []rbacv1.Subject) error {
+	name := organizationAccessName(organization)
+
+	clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	_, err := r.ensureObject(ctx, clusterRole, func() error {
+		if clusterRole.ResourceVersion != "" && !metav1.IsControlledBy(clusterRole, organization) {
+			return fmt.Errorf("ClusterRole %s exists and is not controlled by the organization", name)
+		}
+		clusterRole.SetLabels(childLabels(clusterRole.GetLabels(), organization))
+		clusterRole.Rules = []rbacv1.PolicyRule{{
+			APIGroups:     []string{securityv1alpha1.GroupVersion.Group},
+			Resources:     []string{"organizations"},
+			ResourceNames: []string{organization.Name},
+			Verbs:         []string{"get", "watch"},
+		}}
+		return ctrl.SetControllerReference(organization, clusterRole, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	_, err = r.ensureObject(ctx, clusterRoleBinding, func() error {
+		if clusterRoleBinding.ResourceVersion != "" && !metav1.IsControlledBy(clusterRoleBinding, organization) {
+			return fmt.Errorf("ClusterRoleBinding %s exists and is not controlled by the organization", name)
+		}
+		clusterRoleBinding.SetLabels(childLabels(clusterRoleBinding.GetLabels(), organization))
+		clusterRoleBinding.RoleRef = rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     name,
+		}
+		clusterRoleBinding.Subjects = subjects
+		return ctrl.SetControllerReference(organization, clusterRoleBinding, r.Scheme)
+	})
+	return err
+}
+
+// deleteOrganizationAccess deletes the ClusterRole and ClusterRoleBinding
+// granting the members read access to the organization. Objects of the same
+// name that are not controlled by the organization are left in place.
+func (r *OrganizationReconciler) deleteOrganizationAccess(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	name := organizationAccessName(organization)
+
+	for _, object := range []client.Object{
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}},
+	} {
+		if err := r.deleteObject(ctx, organization, object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -19,15 +19,23 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -40,6 +48,16 @@ const (
 	newFinalizer = "organization.giantswarm.io/finalizer"
 )
 
+const (
+	// DefaultResyncPeriod is the default interval after which organizations
+	// are reconciled again without any change to them.
+	DefaultResyncPeriod = 10 * time.Hour
+
+	// resyncPeriodJitter spreads the periodic reconciliations of the
+	// organizations over up to a tenth of the resync period.
+	resyncPeriodJitter = 0.1
+)
+
 var (
 	organizationsTotal = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -47,16 +65,77 @@ var (
 			Help: "The total number of existing organizations",
 		},
 	)
+	namespaceDriftRepairsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "organization_namespace_drift_repairs_total",
+			Help: "The total number of repaired drifts of operator managed namespace metadata",
+		},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(organizationsTotal)
+	metrics.Registry.MustRegister(organizationsTotal, namespaceDriftRepairsTotal)
 }
 
 // OrganizationReconciler reconciles a Organization object
 type OrganizationReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// NamespaceNameTemplate renders the namespace name of new organizations.
+	// Defaults to org-<name> when nil.
+	NamespaceNameTemplate *NamespaceNameTemplate
+
+	// APIReader reads objects that are not cached by the manager, such as the
+	// contents of adopted namespaces. Defaults to the client when nil.
+	APIReader client.Reader
+
+	// MemberRoles maps the member roles to the ClusterRoles bound for them.
+	// Defaults to DefaultMemberRoles when nil.
+	MemberRoles map[string]string
+
+	// NetworkIsolation isolates the namespaces of organizations that do not
+	// override it with NetworkPolicies.
+	NetworkIsolation bool
+	// PlatformNamespaces may reach isolated organization namespaces.
+	PlatformNamespaces []string
+
+	// PodSecurityDefaults are the Pod Security Admission settings of
+	// organizations that do not set them.
+	PodSecurityDefaults securityv1alpha1.PodSecurity
+	// PodSecurityDryRunner checks existing pods against stricter enforce
+	// levels before they are applied. Levels are applied unchecked when nil.
+	PodSecurityDryRunner PodSecurityDryRunner
+
+	// ClassRolloutInterval spaces the organizations reconciled for a changed
+	// OrganizationClass. Defaults to DefaultClassRolloutInterval when zero.
+	ClassRolloutInterval time.Duration
+
+	// AutomationTokenExpiration is the lifetime of the tokens issued to
+	// automation accounts that do not set it. Defaults to
+	// DefaultAutomationTokenExpiration when zero.
+	AutomationTokenExpiration time.Duration
+	// KubeconfigServer and KubeconfigCA are the API server address and CA
+	// bundle written into the kubeconfigs of automation accounts. Automation
+	// accounts are not reconciled without KubeconfigServer.
+	KubeconfigServer string
+	KubeconfigCA     []byte
+
+	// DeletionStuckThreshold is the time after which a deletion that has not
+	// completed is reported as stuck. Defaults to
+	// DefaultDeletionStuckThreshold when zero.
+	DeletionStuckThreshold time.Duration
+	// DeletionTimeout is the time after which a namespace that is not
+	// deleted is no longer polled and the organization reports
+	// DeletionStalled. Defaults to DefaultDeletionTimeout when zero.
+	DeletionTimeout time.Duration
+
+	// ResyncPeriod is the interval after which organizations are reconciled
+	// again to verify their managed objects without any watch event. It does
+	// not change the resync period of the manager cache. Defaults to
+	// DefaultResyncPeriod when zero.
+	ResyncPeriod time.Duration
 }
 
 func (r *OrganizationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -73,52 +152,44 @@ func (r *OrganizationReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return r.reconcileDelete(ctx, organization)
 	}
 
-	// Add finalizer if it doesn't exist
-	if !controllerutil.ContainsFinalizer(organization, newFinalizer) {
-		patch := client.MergeFrom(organization.DeepCopy())
-		controllerutil.AddFinalizer(organization, newFinalizer)
-		if err := r.Patch(ctx, organization, patch); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
-		}
+	// Add the finalizer, replacing the legacy one
+	_, replaced, err := migrateFinalizers(ctx, r.Client, organization)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
 	}
-
-	// Create or update the Namespace
-	namespaceName := fmt.Sprintf("org-%s", organization.Name)
-	namespace := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: namespaceName,
-			Labels: map[string]string{
-				"giantswarm.io/organization": organization.Name,
-				"giantswarm.io/managed-by":   "organization-operator",
-			},
-		},
+	if replaced {
+		logger.Info("Migrated legacy finalizer of Organization")
+		r.Recorder.Eventf(organization, corev1.EventTypeNormal, eventReasonFinalizerMigrated,
+			"Replaced legacy finalizer %s with %s", oldFinalizer, newFinalizer)
 	}
 
-	if err := ctrl.SetControllerReference(organization, namespace, r.Scheme); err != nil {
-		return ctrl.Result{}, fmt.Errorf("unable to set controller reference on Namespace: %w", err)
+	class, err := r.resolveClass(ctx, organization)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	operationResult, err := ctrl.CreateOrUpdate(ctx, r.Client, namespace, func() error {
-		namespace.Labels = map[string]string{
-			"giantswarm.io/organization": organization.Name,
-			"giantswarm.io/managed-by":   "organization-operator",
+	original := organization.DeepCopy()
+	var reconcileErr error
+	if setClassCondition(organization, class) {
+		reconcileErr = r.reconcileNamespace(ctx, organization)
+		if reconcileErr == nil {
+			reconcileErr = r.reconcileSuspension(ctx, organization)
+		}
+		// The objects managed for a suspended organization are left as they
+		// are until it is unsuspended.
+		if reconcileErr == nil && !organization.Spec.Suspended {
+			reconcileErr = r.reconcileChildren(ctx, organization)
 		}
-		return nil
-	})
-
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to create or update Namespace: %w", err)
 	}
 
-	logger.Info("Namespace reconciled", "result", operationResult)
-
-	// Update Organization status
-	if organization.Status.Namespace != namespaceName {
-		patch := client.MergeFrom(organization.DeepCopy())
-		organization.Status.Namespace = namespaceName
-		if err := r.Status().Patch(ctx, organization, patch); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to update Organization status: %w", err)
-		}
+	setReadyCondition(organization)
+	setPhase(organization, reconcileErr)
+	organization.Status.ObservedGeneration = organization.Generation
+	if err := r.patchStatus(ctx, original, organization); err != nil {
+		return ctrl.Result{}, err
+	}
+	if reconcileErr != nil {
+		return ctrl.Result{}, reconcileErr
 	}
 
 	if err := r.updateOrganizationCount(ctx); err != nil {
@@ -126,55 +197,129 @@ func (r *OrganizationReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{Requeue: true}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.requeueAfter(organization)}, nil
+}
+
+// requeueAfter returns the delay after which the organization is reconciled
+// again without any change to it. Organizations are resynced after the
+// jittered resync period, so that drift of their managed objects is repaired
+// even when no watch event reports it. Held back Pod Security levels are
+// rechecked sooner and tokens of automation accounts are rotated before they
+// expire, unless the organization is suspended.
+func (r *OrganizationReconciler) requeueAfter(organization *securityv1alpha1.Organization) time.Duration {
+	after := wait.Jitter(r.resyncPeriod(), resyncPeriodJitter)
+	if podSecurityHeldBack(organization) {
+		after = min(after, podSecurityRecheckInterval)
+	}
+	if rotation, ok := nextTokenRotation(organization); ok && !organization.Spec.Suspended {
+		after = min(after, max(time.Until(rotation), time.Second))
+	}
+	return after
+}
+
+func (r *OrganizationReconciler) resyncPeriod() time.Duration {
+	if r.ResyncPeriod == 0 {
+		return DefaultResyncPeriod
+	}
+	return r.ResyncPeriod
+}
+
+// patchStatus patches the Organization status if it differs from the original.
+func (r *OrganizationReconciler) patchStatus(ctx context.Context, original, organization *securityv1alpha1.Organization) error {
+	if equality.Semantic.DeepEqual(original.Status, organization.Status) {
+		return nil
+	}
+	if err := r.Status().Patch(ctx, organization, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to update Organization status: %w", err)
+	}
+	return nil
 }
 
 func (r *OrganizationReconciler) reconcileDelete(ctx context.Context, organization *securityv1alpha1.Organization) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
+	original := organization.DeepCopy()
+	reason, message := reasonNamespaceDeleting, "Waiting for the organization namespace to be deleted"
+	if releasesNamespace(organization) {
+		reason, message = reasonNamespaceReleasing,
+			fmt.Sprintf("Releasing the organization namespace according to the %s deletion policy", organization.Spec.DeletionPolicy)
+	}
+	if meta.FindStatusCondition(organization.Status.Conditions, securityv1alpha1.TerminatingCondition) == nil {
+		r.Recorder.Event(organization, corev1.EventTypeNormal, eventReasonDeletionStarted, message)
+	}
+	setCondition(organization, securityv1alpha1.TerminatingCondition, metav1.ConditionTrue, reason, message)
+	setCondition(organization, securityv1alpha1.ReadyCondition, metav1.ConditionFalse, reasonDeleting,
+		"Organization is being deleted")
+	organization.Status.Phase = securityv1alpha1.OrganizationPhaseTerminating
+
 	// Use the namespace name from the organization status
 	namespaceName := organization.Status.Namespace
-	if namespaceName != "" {
-		// Attempt to delete the namespace without checking for its existence first
-		namespace := &corev1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: namespaceName,
-			},
-		}
-		err := r.Delete(ctx, namespace)
-		if err == nil {
-			// If the namespace was found and delete was triggered, requeue
-			log.Info("Namespace deletion triggered, requeuing")
-			return ctrl.Result{Requeue: true}, nil
+	var namespace *corev1.Namespace
+	if namespaceName != "" && !releasesNamespace(organization) {
+		namespace = &corev1.Namespace{}
+		err := r.Get(ctx, client.ObjectKey{Name: namespaceName}, namespace)
+		if errors.IsNotFound(err) {
+			namespace = nil
+		} else if err != nil {
+			err = fmt.Errorf("failed to get Namespace %s: %w", namespaceName, err)
+			r.recordFailure(organization, eventReasonDeletionFailed, err)
+			return ctrl.Result{}, err
 		}
-		if !errors.IsNotFound(err) {
-			log.Error(err, "Failed to delete associated namespace")
+	}
+
+	if err := r.setTerminationStatus(ctx, organization, namespace); err != nil {
+		log.Error(err, "Failed to report deletion progress")
+		r.recordFailure(organization, eventReasonDeletionFailed, err)
+		return ctrl.Result{}, err
+	}
+	organization.Status.ObservedGeneration = organization.Generation
+	if err := r.patchStatus(ctx, original, organization); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if namespaceName != "" && releasesNamespace(organization) {
+		// Release the namespace so that it is not garbage collected together
+		// with the organization
+		if err := r.releaseNamespace(ctx, organization, namespaceName); err != nil {
+			log.Error(err, "Failed to release associated namespace")
+			r.recordFailure(organization, eventReasonDeletionFailed, err)
 			return ctrl.Result{}, err
 		}
+		log.Info("Associated namespace released", "deletionPolicy", organization.Spec.DeletionPolicy)
+	} else if namespace != nil {
+		if namespace.DeletionTimestamp == nil {
+			if err := r.Delete(ctx, namespace); client.IgnoreNotFound(err) != nil {
+				log.Error(err, "Failed to delete associated namespace")
+				r.recordFailure(organization, eventReasonDeletionFailed, fmt.Errorf("failed to delete Namespace %s: %w", namespaceName, err))
+				return ctrl.Result{}, err
+			}
+			log.Info("Namespace deletion triggered, requeuing")
+		} else {
+			log.Info("Waiting for namespace deletion", "remaining", organization.Status.Termination.Remaining)
+		}
+		return ctrl.Result{RequeueAfter: r.deletionRequeueAfter(organization)}, nil
+	} else if namespaceName != "" {
 		// If the namespace is not found, we can proceed to remove the finalizer
 		log.Info("Associated namespace not found or already deleted")
 	}
 
-	// Remove old finalizer if it exists
-	if controllerutil.ContainsFinalizer(organization, oldFinalizer) {
-		log.Info("Removing old finalizer from Organization")
-		controllerutil.RemoveFinalizer(organization, oldFinalizer)
-		if err := r.Update(ctx, organization); err != nil {
-			log.Error(err, "Failed to remove old finalizer")
-			return ctrl.Result{}, err
-		}
+	// Revoke the read access of the members to the organization
+	if err := r.deleteOrganizationAccess(ctx, organization); err != nil {
+		log.Error(err, "Failed to delete organization access")
+		r.recordFailure(organization, eventReasonDeletionFailed, err)
+		return ctrl.Result{}, err
 	}
 
-	// Remove new finalizer if it exists
-	if controllerutil.ContainsFinalizer(organization, newFinalizer) {
-		log.Info("Removing new finalizer from Organization")
-		controllerutil.RemoveFinalizer(organization, newFinalizer)
-		if err := r.Update(ctx, organization); err != nil {
-			log.Error(err, "Failed to remove new finalizer")
-			return ctrl.Result{}, err
-		}
+	// Remove the finalizer and the legacy one
+	if _, err := patchFinalizers(ctx, r.Client, organization, removeFinalizers); client.IgnoreNotFound(err) != nil {
+		log.Error(err, "Failed to remove finalizers")
+		r.recordFailure(organization, eventReasonDeletionFailed, fmt.Errorf("failed to remove finalizers: %w", err))
+		return ctrl.Result{}, err
 	}
+	r.Recorder.Event(organization, corev1.EventTypeNormal, eventReasonDeletionCompleted,
+		"Organization is deleted and the access of its members is revoked")
 
+	forgetTermination(organization)
 	if err := r.updateOrganizationCount(ctx); err != nil {
 		log.Error(err, "Failed to update organization count")
 		return ctrl.Result{}, err
@@ -196,8 +341,18 @@ func (r *OrganizationReconciler) updateOrganizationCount(ctx context.Context) er
 // SetupWithManager sets up the controller with the Manager.
 func (r *OrganizationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&securityv1alpha1.Organization{}).
-		Owns(&corev1.Namespace{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		For(&securityv1alpha1.Organization{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(namespaceToOrganization),
+			builder.WithPredicates(namespaceDriftPredicate())).
+		Watches(&securityv1alpha1.OrganizationClass{}, r.classRolloutHandler(),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&corev1.ResourceQuota{}).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.LimitRange{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&rbacv1.ClusterRole{}).
+		Owns(&rbacv1.ClusterRoleBinding{}).
 		Complete(r)
 }
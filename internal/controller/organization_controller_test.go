@@ -26,9 +26,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
@@ -54,8 +57,9 @@ var _ = Describe("Organization controller", func() {
 			Expect(k8sClient.Create(ctx, org1)).To(Succeed())
 
 			reconciler := &OrganizationReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
 			}
 
 			_, err := reconciler.Reconcile(ctx, reconcile.Request{
@@ -153,8 +157,9 @@ var _ = Describe("Organization controller", func() {
 			Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
 
 			reconciler := &OrganizationReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
 			}
 
 			// Trigger deletion
@@ -218,8 +223,9 @@ var _ = Describe("Organization controller", func() {
 			Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
 
 			reconciler := &OrganizationReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
 			}
 
 			// Trigger deletion
@@ -259,4 +265,576 @@ var _ = Describe("Organization controller", func() {
 			}, timeout, interval).Should(BeTrue())
 		})
 	})
+	Context("When reporting Organization status", func() {
+		It("Should set observedGeneration and the Ready and NamespaceReady conditions", func() {
+			ctx := context.Background()
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-conditions",
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			reconciler := &OrganizationReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-conditions"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			updatedOrg := &securityv1alpha1.Organization{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-conditions"}, updatedOrg)).To(Succeed())
+			Expect(updatedOrg.Status.ObservedGeneration).To(Equal(updatedOrg.Generation))
+			Expect(meta.IsStatusConditionTrue(updatedOrg.Status.Conditions, securityv1alpha1.NamespaceReadyCondition)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(updatedOrg.Status.Conditions, securityv1alpha1.ReadyCondition)).To(BeTrue())
+			Expect(updatedOrg.Status.Phase).To(Equal(securityv1alpha1.OrganizationPhaseActive))
+
+			By("Deleting the organization")
+			Expect(k8sClient.Delete(ctx, updatedOrg)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-conditions"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			terminatingOrg := &securityv1alpha1.Organization{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-conditions"}, terminatingOrg)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(terminatingOrg.Status.Conditions, securityv1alpha1.TerminatingCondition)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(terminatingOrg.Status.Conditions, securityv1alpha1.ReadyCondition)).To(BeTrue())
+			Expect(terminatingOrg.Status.Phase).To(Equal(securityv1alpha1.OrganizationPhaseTerminating))
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-conditions"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKey{Name: "test-conditions"}, &securityv1alpha1.Organization{}))).To(BeTrue())
+		})
+	})
+	Context("When an Organization has descriptive spec fields", func() {
+		It("Should mirror them onto the Namespace annotations", func() {
+			ctx := context.Background()
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-spec",
+				},
+				Spec: securityv1alpha1.OrganizationSpec{
+					DisplayName: "Test Spec GmbH – Zürich",
+					Description: "An organization used in tests",
+					Contacts: []securityv1alpha1.OrganizationContact{
+						{Name: "Jane Doe", Email: "jane@example.com", Role: "technical"},
+					},
+					ExternalIDs: []securityv1alpha1.ExternalID{
+						{System: "crm", ID: "0012345"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			reconciler := &OrganizationReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-spec"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-spec"}, namespace)).To(Succeed())
+			Expect(namespace.Annotations).To(HaveKeyWithValue("organization.giantswarm.io/display-name", "Test Spec GmbH – Zürich"))
+			Expect(namespace.Annotations).To(HaveKeyWithValue("organization.giantswarm.io/description", "An organization used in tests"))
+			Expect(namespace.Annotations).To(HaveKeyWithValue("organization.giantswarm.io/external-id.crm", "0012345"))
+
+			By("Clearing the description and external IDs")
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-spec"}, org)).To(Succeed())
+			org.Spec.Description = ""
+			org.Spec.ExternalIDs = nil
+			Expect(k8sClient.Update(ctx, org)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-spec"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-spec"}, namespace)).To(Succeed())
+			Expect(namespace.Annotations).To(HaveKey("organization.giantswarm.io/display-name"))
+			Expect(namespace.Annotations).NotTo(HaveKey("organization.giantswarm.io/description"))
+			Expect(namespace.Annotations).NotTo(HaveKey("organization.giantswarm.io/external-id.crm"))
+		})
+	})
+	Context("When an Organization requests Namespace labels and annotations", func() {
+		It("Should propagate them and remove them again once dropped from the spec", func() {
+			ctx := context.Background()
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-metadata",
+				},
+				Spec: securityv1alpha1.OrganizationSpec{
+					NamespaceMetadata: &securityv1alpha1.NamespaceMetadata{
+						Labels: map[string]string{
+							"example.com/cost-center": "1234",
+							"istio-injection":         "enabled",
+						},
+						Annotations: map[string]string{
+							"example.com/owner": "team-a",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			reconciler := &OrganizationReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-metadata"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-metadata"}, namespace)).To(Succeed())
+			Expect(namespace.Labels).To(HaveKeyWithValue("example.com/cost-center", "1234"))
+			Expect(namespace.Labels).To(HaveKeyWithValue("istio-injection", "enabled"))
+			Expect(namespace.Labels).To(HaveKeyWithValue("giantswarm.io/organization", "test-metadata"))
+			Expect(namespace.Annotations).To(HaveKeyWithValue("example.com/owner", "team-a"))
+
+			By("Removing a label and an annotation from the spec")
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-metadata"}, org)).To(Succeed())
+			delete(org.Spec.NamespaceMetadata.Labels, "istio-injection")
+			org.Spec.NamespaceMetadata.Annotations = nil
+			Expect(k8sClient.Update(ctx, org)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-metadata"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-metadata"}, namespace)).To(Succeed())
+			Expect(namespace.Labels).To(HaveKeyWithValue("example.com/cost-center", "1234"))
+			Expect(namespace.Labels).NotTo(HaveKey("istio-injection"))
+			Expect(namespace.Annotations).NotTo(HaveKey("example.com/owner"))
+		})
+	})
+	Context("When other actors label the Organization Namespace", func() {
+		It("Should keep labels and annotations it does not own", func() {
+			ctx := context.Background()
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-foreign",
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			reconciler := &OrganizationReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-foreign"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Adding foreign labels and annotations to the Namespace")
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-foreign"}, namespace)).To(Succeed())
+			patch := client.MergeFrom(namespace.DeepCopy())
+			namespace.Labels["pod-security.kubernetes.io/enforce"] = "restricted"
+			namespace.Annotations["example.com/added-by"] = "admin"
+			Expect(k8sClient.Patch(ctx, namespace, patch)).To(Succeed())
+
+			By("Changing the Organization spec")
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-foreign"}, org)).To(Succeed())
+			org.Spec.DisplayName = "Foreign Test"
+			org.Spec.NamespaceMetadata = &securityv1alpha1.NamespaceMetadata{
+				Labels: map[string]string{"example.com/tier": "gold"},
+			}
+			Expect(k8sClient.Update(ctx, org)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-foreign"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-foreign"}, namespace)).To(Succeed())
+			Expect(namespace.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "restricted"))
+			Expect(namespace.Labels).To(HaveKeyWithValue("example.com/tier", "gold"))
+			Expect(namespace.Labels).To(HaveKeyWithValue("giantswarm.io/managed-by", "organization-operator"))
+			Expect(namespace.Annotations).To(HaveKeyWithValue("example.com/added-by", "admin"))
+			Expect(namespace.Annotations).To(HaveKeyWithValue("organization.giantswarm.io/display-name", "Foreign Test"))
+		})
+	})
+	Context("When the Organization Namespace drifts", func() {
+		It("Should repair operator owned metadata, record an Event and count the repair", func() {
+			ctx := context.Background()
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-drift",
+				},
+				Spec: securityv1alpha1.OrganizationSpec{
+					NamespaceMetadata: &securityv1alpha1.NamespaceMetadata{
+						Labels: map[string]string{"example.com/cost-center": "1234"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &OrganizationReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-drift"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Normal NamespaceCreated Created namespace org-test-drift")))
+
+			By("Tampering with the operator owned labels")
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-drift"}, namespace)).To(Succeed())
+			oldNamespace := namespace.DeepCopy()
+			patch := client.MergeFrom(namespace.DeepCopy())
+			namespace.Labels["example.com/cost-center"] = "9999"
+			delete(namespace.Labels, "giantswarm.io/managed-by")
+			Expect(k8sClient.Patch(ctx, namespace, patch)).To(Succeed())
+
+			By("Checking the Namespace predicate and mapping")
+			Expect(namespaceDriftPredicate().Update(event.UpdateEvent{ObjectOld: oldNamespace, ObjectNew: namespace})).To(BeTrue())
+			Expect(namespaceToOrganization(ctx, namespace)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-drift"},
+			}))
+
+			unrelated := oldNamespace.DeepCopy()
+			unrelated.Labels["example.com/other"] = "value"
+			Expect(namespaceDriftPredicate().Update(event.UpdateEvent{ObjectOld: oldNamespace, ObjectNew: unrelated})).To(BeFalse())
+
+			repairs := testutil.ToFloat64(namespaceDriftRepairsTotal)
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-drift"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-drift"}, namespace)).To(Succeed())
+			Expect(namespace.Labels).To(HaveKeyWithValue("example.com/cost-center", "1234"))
+			Expect(namespace.Labels).To(HaveKeyWithValue("giantswarm.io/managed-by", "organization-operator"))
+			Expect(testutil.ToFloat64(namespaceDriftRepairsTotal)).To(Equal(repairs + 1))
+			Expect(recorder.Events).To(Receive(ContainSubstring("NamespaceDriftRepaired")))
+		})
+
+		It("Should not count changes of the desired metadata as drift", func() {
+			ctx := context.Background()
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-no-drift",
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &OrganizationReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+			reconcileOrganization := func() {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "test-no-drift"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			reconcileOrganization()
+			Expect(recorder.Events).To(Receive(ContainSubstring("NamespaceCreated")))
+
+			By("Changing the Pod Security defaults of the operator")
+			repairs := testutil.ToFloat64(namespaceDriftRepairsTotal)
+			reconciler.PodSecurityDefaults = securityv1alpha1.PodSecurity{Enforce: securityv1alpha1.PodSecurityLevelBaseline}
+			reconcileOrganization()
+
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-no-drift"}, namespace)).To(Succeed())
+			Expect(namespace.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "baseline"))
+			Expect(testutil.ToFloat64(namespaceDriftRepairsTotal)).To(Equal(repairs))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+	Context("When deleting Organizations with a deletion policy", func() {
+		DescribeTable("Should release the Namespace instead of deleting it",
+			func(name string, policy securityv1alpha1.DeletionPolicy, keepsLabels bool) {
+				ctx := context.Background()
+				org := &securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+					},
+					Spec: securityv1alpha1.OrganizationSpec{
+						DeletionPolicy: policy,
+						NamespaceMetadata: &securityv1alpha1.NamespaceMetadata{
+							Labels: map[string]string{"example.com/cost-center": "1234"},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+				reconciler := &OrganizationReconciler{
+					Client:   k8sClient,
+					Scheme:   k8sClient.Scheme(),
+					Recorder: &record.FakeRecorder{},
+				}
+
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: name},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				namespace := &corev1.Namespace{}
+				Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-" + name}, namespace)).To(Succeed())
+				Expect(namespace.OwnerReferences).To(HaveLen(1))
+				patch := client.MergeFrom(namespace.DeepCopy())
+				namespace.Labels["example.com/foreign"] = "kept"
+				Expect(k8sClient.Patch(ctx, namespace, patch)).To(Succeed())
+
+				By("Deleting the organization")
+				Expect(k8sClient.Get(ctx, client.ObjectKey{Name: name}, org)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, org)).To(Succeed())
+
+				_, err = reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: name},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKey{Name: name}, &securityv1alpha1.Organization{}))).To(BeTrue())
+
+				Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-" + name}, namespace)).To(Succeed())
+				Expect(namespace.OwnerReferences).To(BeEmpty())
+				Expect(namespace.Labels).To(HaveKeyWithValue("example.com/foreign", "kept"))
+				if keepsLabels {
+					Expect(namespace.Labels).To(HaveKeyWithValue("giantswarm.io/organization", name))
+					Expect(namespace.Labels).To(HaveKeyWithValue("example.com/cost-center", "1234"))
+					Expect(namespace.Annotations).To(HaveKeyWithValue("organization.giantswarm.io/released", name))
+				} else {
+					Expect(namespace.Labels).NotTo(HaveKey("giantswarm.io/organization"))
+					Expect(namespace.Labels).NotTo(HaveKey("giantswarm.io/managed-by"))
+					Expect(namespace.Labels).NotTo(HaveKey("example.com/cost-center"))
+					Expect(namespace.Annotations).NotTo(HaveKey("organization.giantswarm.io/managed-labels"))
+					Expect(namespace.Annotations).NotTo(HaveKey("organization.giantswarm.io/released"))
+				}
+			},
+			Entry("Retain strips the managed metadata", "test-retain", securityv1alpha1.DeletionPolicyRetain, false),
+			Entry("Orphan keeps the managed metadata", "test-orphan", securityv1alpha1.DeletionPolicyOrphan, true),
+		)
+	})
+	Context("When the Organization Namespace already exists", func() {
+		var (
+			ctx      context.Context
+			recorder *record.FakeRecorder
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			recorder = record.NewFakeRecorder(10)
+		})
+
+		newReconciler := func() *OrganizationReconciler {
+			return &OrganizationReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+		}
+
+		It("Should refuse a Namespace released by another Organization without opt-in", func() {
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "org-test-released",
+					Labels: map[string]string{
+						"giantswarm.io/organization": "test-released",
+						"giantswarm.io/managed-by":   "organization-operator",
+					},
+					Annotations: map[string]string{"organization.giantswarm.io/released": "test-released"},
+				},
+			})).To(Succeed())
+
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-released",
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			By("Reconciling without opt-in")
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-released"},
+			})
+			Expect(err).To(MatchError(reconcile.TerminalError(nil)))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning NamespaceNotOwned")))
+
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-released"}, namespace)).To(Succeed())
+			Expect(metav1.GetControllerOf(namespace)).To(BeNil())
+
+			By("Opting in to adopt the Namespace")
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-released"}, org)).To(Succeed())
+			org.Spec.AdoptNamespace = true
+			Expect(k8sClient.Update(ctx, org)).To(Succeed())
+			_, err = newReconciler().Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-released"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-released"}, namespace)).To(Succeed())
+			Expect(metav1.GetControllerOf(namespace)).NotTo(BeNil())
+			Expect(namespace.Annotations).NotTo(HaveKey("organization.giantswarm.io/released"))
+		})
+
+		It("Should refuse the Namespace without opt-in and adopt it once opted in", func() {
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "org-test-adopt",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "v1",
+						Kind:       "ConfigMap",
+						Name:       "owner",
+						UID:        "owner-uid",
+					}},
+				},
+			})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "org-test-adopt"},
+			})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "org-test-adopt"},
+			})).To(Succeed())
+
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-adopt",
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			By("Reconciling without opt-in")
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-adopt"},
+			})
+			Expect(err).To(MatchError(reconcile.TerminalError(nil)))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning NamespaceNotOwned")))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-adopt"}, org)).To(Succeed())
+			namespaceReady := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.NamespaceReadyCondition)
+			Expect(namespaceReady).NotTo(BeNil())
+			Expect(namespaceReady.Status).To(Equal(metav1.ConditionFalse))
+			Expect(namespaceReady.Reason).To(Equal("NamespaceNotOwned"))
+			Expect(org.Status.Namespace).To(BeEmpty())
+
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-adopt"}, namespace)).To(Succeed())
+			Expect(metav1.GetControllerOf(namespace)).To(BeNil())
+			Expect(namespace.Labels).NotTo(HaveKey("giantswarm.io/organization"))
+
+			By("Opting in to adopt the Namespace")
+			org.Spec.AdoptNamespace = true
+			Expect(k8sClient.Update(ctx, org)).To(Succeed())
+			_, err = newReconciler().Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-adopt"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-adopt"}, namespace)).To(Succeed())
+			Expect(metav1.GetControllerOf(namespace)).NotTo(BeNil())
+			Expect(metav1.GetControllerOf(namespace).Name).To(Equal("test-adopt"))
+			Expect(namespace.OwnerReferences).To(HaveLen(2))
+			Expect(namespace.Labels).To(HaveKeyWithValue("giantswarm.io/organization", "test-adopt"))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-adopt"}, org)).To(Succeed())
+			Expect(org.Status.Namespace).To(Equal("org-test-adopt"))
+			Expect(meta.IsStatusConditionTrue(org.Status.Conditions, securityv1alpha1.NamespaceReadyCondition)).To(BeTrue())
+			Expect(org.Status.Adoption).NotTo(BeNil())
+			Expect(org.Status.Adoption.AdoptedAt).NotTo(BeNil())
+			Expect(org.Status.Adoption.Inventory).To(Equal(map[string]int32{"configmaps": 1, "serviceaccounts": 1}))
+			Expect(org.Status.Adoption.ConflictingOwners).To(ConsistOf("ConfigMap/owner (v1)"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("NamespaceAdopted")))
+		})
+
+		It("Should refuse to adopt a Namespace controlled by another object", func() {
+			controller := true
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "org-test-conflict",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "example.com/v1",
+						Kind:       "Tenant",
+						Name:       "tenant",
+						UID:        "tenant-uid",
+						Controller: &controller,
+					}},
+				},
+			})).To(Succeed())
+
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-conflict",
+				},
+				Spec: securityv1alpha1.OrganizationSpec{
+					AdoptNamespace: true,
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-conflict"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("controlled by Tenant/tenant")))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-conflict"}, org)).To(Succeed())
+			namespaceReady := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.NamespaceReadyCondition)
+			Expect(namespaceReady).NotTo(BeNil())
+			Expect(namespaceReady.Reason).To(Equal("NamespaceOwnershipConflict"))
+			Expect(org.Status.Adoption).NotTo(BeNil())
+			Expect(org.Status.Adoption.AdoptedAt).To(BeNil())
+			Expect(org.Status.Adoption.ConflictingOwners).To(ConsistOf("Tenant/tenant (example.com/v1)"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("NamespaceOwnershipConflict")))
+
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "org-test-conflict"}, namespace)).To(Succeed())
+			Expect(namespace.OwnerReferences).To(HaveLen(1))
+		})
+	})
+
+	Context("When an Organization is reconciled", func() {
+		It("Should requeue it after the jittered resync period", func() {
+			ctx := context.Background()
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-resync-period",
+				},
+			}
+			Expect(k8sClient.Create(ctx, org)).To(Succeed())
+
+			reconciler := &OrganizationReconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Recorder:     &record.FakeRecorder{},
+				ResyncPeriod: 5 * time.Minute,
+			}
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-resync-period"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(And(
+				BeNumerically(">=", 5*time.Minute),
+				BeNumerically("<=", 5*time.Minute+30*time.Second),
+			))
+		})
+	})
 })
@@ -0,0 +1,619 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// Condition reasons used by the OrganizationTemplate controller.
+const (
+	reasonTemplateApplied     = "TemplateApplied"
+	reasonTemplateApplyFailed = "TemplateApplyFailed"
+
+	reasonInvalidOrganizationSelector = "InvalidOrganizationSelector"
+)
+
+// OrganizationTemplateReconciler reconciles a OrganizationTemplate object
+type OrganizationTemplateReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// controller and appliedCache watch the kinds of the applied objects,
+	// which are only known once a template applies them.
+	controller   controller.Controller
+	appliedCache cache.Cache
+	watchesMu    sync.Mutex
+	watches      map[schema.GroupVersionKind]bool
+}
+
+// templateData is the data the string values of the template manifests are
+// rendered with.
+type templateData struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	Spec      securityv1alpha1.OrganizationSpec
+}
+
+// templateSpecError reports a template that cannot be applied as it is
+// written, which retrying does not fix.
+type templateSpecError struct {
+	message string
+}
+
+func (e *templateSpecError) Error() string {
+	return e.message
+}
+
+func (r *OrganizationTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	orgTemplate := &securityv1alpha1.OrganizationTemplate{}
+	if err := r.Get(ctx, req.NamespacedName, orgTemplate); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// The applied objects are controlled by the template and garbage collected with it.
+	if orgTemplate.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	original := orgTemplate.DeepCopy()
+	var reconcileErr error
+	selector, err := metav1.LabelSelectorAsSelector(&orgTemplate.Spec.OrganizationSelector)
+	if err != nil {
+		// Keep the previous status of the organizations until the selector is fixed.
+		setTemplateCondition(orgTemplate, metav1.ConditionFalse, reasonInvalidOrganizationSelector,
+			fmt.Sprintf("Invalid organization selector: %v", err))
+	} else {
+		reconcileErr = r.reconcileOrganizations(ctx, orgTemplate, selector)
+		setTemplateReadyCondition(orgTemplate)
+	}
+	orgTemplate.Status.ObservedGeneration = orgTemplate.Generation
+
+	if !equality.Semantic.DeepEqual(original.Status, orgTemplate.Status) {
+		if err := r.Status().Patch(ctx, orgTemplate, client.MergeFrom(original)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update OrganizationTemplate status: %w", err)
+		}
+	}
+	return ctrl.Result{}, reconcileErr
+}
+
+// reconcileOrganizations applies the template to the selected organizations
+// and prunes it from the organizations that are no longer selected, recording
+// the outcome in the template status.
+func (r *OrganizationTemplateReconciler) reconcileOrganizations(ctx context.Context, orgTemplate *securityv1alpha1.OrganizationTemplate, selector labels.Selector) error {
+	logger := log.FromContext(ctx)
+
+	previous := map[string]securityv1alpha1.TemplateOrganizationStatus{}
+	for _, status := range orgTemplate.Status.Organizations {
+		previous[status.Name] = status
+	}
+
+	organizations := &securityv1alpha1.OrganizationList{}
+	if err := r.List(ctx, organizations, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	var errs []error
+	var statuses []securityv1alpha1.TemplateOrganizationStatus
+	for i := range organizations.Items {
+		organization := &organizations.Items[i]
+		previousStatus := previous[organization.Name]
+		delete(previous, organization.Name)
+		// The namespace of a deleted organization is deleted or released
+		// together with it.
+		if organization.GetDeletionTimestamp() != nil {
+			continue
+		}
+		// The objects of a suspended organization are left as they are
+		// until it is unsuspended.
+		if organization.Spec.Suspended {
+			status := previousStatus
+			status.Name = organization.Name
+			status.Message = "Organization is suspended"
+			statuses = append(statuses, status)
+			continue
+		}
+
+		status, err := r.applyTemplate(ctx, orgTemplate, organization, previousStatus)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		statuses = append(statuses, status)
+	}
+	orgTemplate.Status.Selected = int32(len(statuses))
+
+	for name, status := range previous {
+		if err := r.pruneObjects(ctx, orgTemplate, status.Namespace, status.Inventory); err != nil {
+			status.Applied = false
+			status.Message = fmt.Sprintf("Failed to prune objects of organization no longer selected: %v", err)
+			statuses = append(statuses, status)
+			errs = append(errs, err)
+			continue
+		}
+		logger.Info("Pruned template from organization", "organization", name)
+	}
+
+	slices.SortFunc(statuses, func(a, b securityv1alpha1.TemplateOrganizationStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	orgTemplate.Status.Organizations = statuses
+	return errors.Join(errs...)
+}
+
+// applyTemplate applies the objects of the template into the organization
+// namespace, and prunes the objects of the previous inventory that are no
+// longer part of the template.
+func (r *OrganizationTemplateReconciler) applyTemplate(ctx context.Context, orgTemplate *securityv1alpha1.OrganizationTemplate, organization *securityv1alpha1.Organization, previous securityv1alpha1.TemplateOrganizationStatus) (securityv1alpha1.TemplateOrganizationStatus, error) {
+	status := securityv1alpha1.TemplateOrganizationStatus{
+		Name:      organization.Name,
+		Namespace: organization.Status.Namespace,
+		Inventory: previous.Inventory,
+	}
+	if status.Namespace == "" {
+		status.Message = "Waiting for the organization namespace"
+		return status, nil
+	}
+
+	objects, err := renderTemplate(orgTemplate, organization)
+	if err != nil {
+		status.Message = err.Error()
+		return status, nil
+	}
+
+	var errs []error
+	var specErrs []string
+	inventory := make([]securityv1alpha1.TemplateObjectReference, 0, len(objects))
+	for _, object := range objects {
+		ref := securityv1alpha1.TemplateObjectReference{
+			APIVersion: object.GetAPIVersion(),
+			Kind:       object.GetKind(),
+			Name:       object.GetName(),
+		}
+		if err := r.applyObject(ctx, orgTemplate, organization, object); err != nil {
+			var specErr *templateSpecError
+			if errors.As(err, &specErr) {
+				specErrs = append(specErrs, err.Error())
+				continue
+			}
+			errs = append(errs, err)
+		} else if err := r.watchAppliedKind(object.GroupVersionKind()); err != nil {
+			errs = append(errs, err)
+		}
+		inventory = append(inventory, ref)
+	}
+
+	var removed []securityv1alpha1.TemplateObjectReference
+	for _, ref := range previous.Inventory {
+		if !containsTemplateObject(inventory, ref) {
+			removed = append(removed, ref)
+		}
+	}
+	if err := r.pruneObjects(ctx, orgTemplate, status.Namespace, removed); err != nil {
+		// Keep the objects that failed to be pruned in the inventory.
+		inventory = append(inventory, removed...)
+		errs = append(errs, err)
+	}
+	status.Inventory = inventory
+
+	messages := specErrs
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	status.Applied = len(messages) == 0
+	status.Message = strings.Join(messages, "; ")
+	return status, errors.Join(errs...)
+}
+
+// renderTemplate renders the manifests of the template for the organization.
+func renderTemplate(orgTemplate *securityv1alpha1.OrganizationTemplate, organization *securityv1alpha1.Organization) ([]*unstructured.Unstructured, error) {
+	data := templateData{
+		Name:      organization.Name,
+		Namespace: organization.Status.Namespace,
+		Labels:    organization.Labels,
+		Spec:      organization.Spec,
+	}
+
+	objects := make([]*unstructured.Unstructured, 0, len(orgTemplate.Spec.Resources))
+	for i, resource := range orgTemplate.Spec.Resources {
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(resource.Raw); err != nil {
+			return nil, fmt.Errorf("resources[%d] is not a valid manifest: %w", i, err)
+		}
+		rendered, err := renderValue(object.Object, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render resources[%d]: %w", i, err)
+		}
+		object.Object = rendered.(map[string]any)
+		if object.GetName() == "" {
+			return nil, fmt.Errorf("resources[%d] has no name", i)
+		}
+		object.SetNamespace(organization.Status.Namespace)
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// renderValue renders the string values found in the given manifest value.
+func renderValue(value any, data templateData) (any, error) {
+	switch value := value.(type) {
+	case string:
+		if !strings.Contains(value, "{{") {
+			return value, nil
+		}
+		tmpl, err := template.New("value").Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		return buf.String(), nil
+	case map[string]any:
+		for key, item := range value {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = rendered
+		}
+		return value, nil
+	case []any:
+		for i, item := range value {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = rendered
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// applyObject creates the object, or merge patches the fields it declares
+// onto the existing object. Objects that exist without being controlled by the
+// template are left untouched, whatever their labels, as tenants may set the
+// template label on their own objects.
+func (r *OrganizationTemplateReconciler) applyObject(ctx context.Context, orgTemplate *securityv1alpha1.OrganizationTemplate, organization *securityv1alpha1.Organization, desired *unstructured.Unstructured) error {
+	namespaced, err := r.IsObjectNamespaced(desired)
+	if err != nil {
+		return &templateSpecError{message: fmt.Sprintf("unknown kind %s: %v", desired.GroupVersionKind(), err)}
+	}
+	if !namespaced {
+		return &templateSpecError{message: fmt.Sprintf("%s %s is not namespaced", desired.GetKind(), desired.GetName())}
+	}
+
+	labels := childLabels(desired.GetLabels(), organization)
+	labels[securityv1alpha1.TemplateLabel] = orgTemplate.Name
+	desired.SetLabels(labels)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(desired.GroupVersionKind())
+	err = r.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if apierrors.IsNotFound(err) {
+		if err := ctrl.SetControllerReference(orgTemplate, desired, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, desired, client.FieldOwner(fieldManager)); err != nil {
+			return fmt.Errorf("failed to create %s %s: %w", desired.GetKind(), desired.GetName(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s: %w", desired.GetKind(), desired.GetName(), err)
+	}
+	if !metav1.IsControlledBy(existing, orgTemplate) {
+		return &templateSpecError{message: fmt.Sprintf("%s %s already exists and is not managed by the template", desired.GetKind(), desired.GetName())}
+	}
+
+	original := existing.DeepCopy()
+	for key, value := range desired.Object {
+		switch key {
+		case "apiVersion", "kind", "metadata", "status":
+			continue
+		}
+		// Fields defaulted by the API server are kept as long as the
+		// declared fields match.
+		if !isSubset(value, existing.Object[key]) {
+			existing.Object[key] = value
+		}
+	}
+	existingLabels := existing.GetLabels()
+	for key, value := range desired.GetLabels() {
+		existingLabels[key] = value
+	}
+	existing.SetLabels(existingLabels)
+	if annotations := desired.GetAnnotations(); len(annotations) > 0 {
+		existingAnnotations := existing.GetAnnotations()
+		if existingAnnotations == nil {
+			existingAnnotations = map[string]string{}
+		}
+		for key, value := range annotations {
+			existingAnnotations[key] = value
+		}
+		existing.SetAnnotations(existingAnnotations)
+	}
+
+	if equality.Semantic.DeepEqual(original, existing) {
+		return nil
+	}
+	if err := r.Patch(ctx, existing, client.MergeFrom(original), client.FieldOwner(fieldManager)); err != nil {
+		return fmt.Errorf("failed to patch %s %s: %w", desired.GetKind(), desired.GetName(), err)
+	}
+	return nil
+}
+
+// isSubset reports whether every field of desired is set to the same value in actual.
+func isSubset(desired, actual any) bool {
+	switch desired := desired.(type) {
+	case map[string]any:
+		actual, ok := actual.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range desired {
+			if !isSubset(value, actual[key]) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		actual, ok := actual.([]any)
+		if !ok || len(actual) != len(desired) {
+			return false
+		}
+		for i := range desired {
+			if !isSubset(desired[i], actual[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return equality.Semantic.DeepEqual(desired, actual)
+	}
+}
+
+// pruneObjects deletes the referenced objects that are controlled by the
+// template.
+func (r *OrganizationTemplateReconciler) pruneObjects(ctx context.Context, orgTemplate *securityv1alpha1.OrganizationTemplate, namespace string, refs []securityv1alpha1.TemplateObjectReference) error {
+	var errs []error
+	for _, ref := range refs {
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+		err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, object)
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get %s %s: %w", ref.Kind, ref.Name, err))
+			continue
+		}
+		if !metav1.IsControlledBy(object, orgTemplate) {
+			continue
+		}
+		if err := r.Delete(ctx, object); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s: %w", ref.Kind, ref.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// containsTemplateObject reports whether the inventory references the same
+// object as ref, regardless of the version of its API group.
+func containsTemplateObject(inventory []securityv1alpha1.TemplateObjectReference, ref securityv1alpha1.TemplateObjectReference) bool {
+	group := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).Group
+	for _, item := range inventory {
+		if item.Kind == ref.Kind && item.Name == ref.Name &&
+			schema.FromAPIVersionAndKind(item.APIVersion, item.Kind).Group == group {
+			return true
+		}
+	}
+	return false
+}
+
+// setTemplateReadyCondition counts the organizations the template is applied
+// to and summarizes them into the Ready condition.
+func setTemplateReadyCondition(orgTemplate *securityv1alpha1.OrganizationTemplate) {
+	var failed []string
+	orgTemplate.Status.Applied = 0
+	for _, status := range orgTemplate.Status.Organizations {
+		if status.Applied {
+			orgTemplate.Status.Applied++
+		} else {
+			failed = append(failed, status.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		setTemplateCondition(orgTemplate, metav1.ConditionFalse, reasonTemplateApplyFailed,
+			"Not applied to organizations: "+strings.Join(failed, ", "))
+		return
+	}
+	setTemplateCondition(orgTemplate, metav1.ConditionTrue, reasonTemplateApplied,
+		fmt.Sprintf("Applied to %d organizations", orgTemplate.Status.Applied))
+}
+
+// setTemplateCondition sets the Ready condition on the template status,
+// stamping it with the template generation.
+func setTemplateCondition(orgTemplate *securityv1alpha1.OrganizationTemplate, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&orgTemplate.Status.Conditions, metav1.Condition{
+		Type:               securityv1alpha1.TemplateReadyCondition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: orgTemplate.Generation,
+	})
+}
+
+// organizationToTemplates enqueues every template, as the selectors may
+// select or stop selecting the organization.
+func (r *OrganizationTemplateReconciler) organizationToTemplates(ctx context.Context, _ client.Object) []reconcile.Request {
+	templates := &securityv1alpha1.OrganizationTemplateList{}
+	if err := r.List(ctx, templates); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list organization templates")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(templates.Items))
+	for _, orgTemplate := range templates.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: orgTemplate.Name}})
+	}
+	return requests
+}
+
+// organizationSelectionPredicate only lets through the organization updates
+// that change which organizations are selected by label, and what is rendered
+// for them.
+func organizationSelectionPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldOrganization, ok := e.ObjectOld.(*securityv1alpha1.Organization)
+			if !ok {
+				return true
+			}
+			newOrganization, ok := e.ObjectNew.(*securityv1alpha1.Organization)
+			if !ok {
+				return true
+			}
+			return oldOrganization.Generation != newOrganization.Generation ||
+				!equality.Semantic.DeepEqual(oldOrganization.Labels, newOrganization.Labels) ||
+				oldOrganization.Status.Namespace != newOrganization.Status.Namespace ||
+				!oldOrganization.DeletionTimestamp.Equal(newOrganization.DeletionTimestamp)
+		},
+	}
+}
+
+// appliedObjectToTemplate maps an applied object to the template that
+// applied it.
+func appliedObjectToTemplate(_ context.Context, object client.Object) []reconcile.Request {
+	name := object.GetLabels()[securityv1alpha1.TemplateLabel]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
+}
+
+// appliedObjectPredicate lets through the updates of applied objects that may
+// have changed what the template declares: a new generation, or any change of
+// objects without generation, such as ConfigMaps, and of their metadata.
+func appliedObjectPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() || e.ObjectNew.GetGeneration() == 0 {
+				return true
+			}
+			return !equality.Semantic.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+				!equality.Semantic.DeepEqual(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations())
+		},
+	}
+}
+
+// watchAppliedKind watches the objects of the given kind applied by the
+// templates, so that changes to them are reverted and deleted objects are
+// applied again. Watches are started once per kind, on the first object of
+// the kind applied.
+func (r *OrganizationTemplateReconciler) watchAppliedKind(gvk schema.GroupVersionKind) error {
+	if r.controller == nil {
+		return nil
+	}
+
+	r.watchesMu.Lock()
+	defer r.watchesMu.Unlock()
+	if r.watches[gvk] {
+		return nil
+	}
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+	err := r.controller.Watch(source.Kind[client.Object](r.appliedCache, object,
+		handler.EnqueueRequestsFromMapFunc(appliedObjectToTemplate), appliedObjectPredicate()))
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", gvk.Kind, err)
+	}
+	if r.watches == nil {
+		r.watches = map[schema.GroupVersionKind]bool{}
+	}
+	r.watches[gvk] = true
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager. The applied
+// objects are watched through a cache of their own, holding only the objects
+// labelled with a template, so that objects of the same kinds applied by
+// others are not cached.
+func (r *OrganizationTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	appliedRequirement, err := labels.NewRequirement(securityv1alpha1.TemplateLabel, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	appliedCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		HTTPClient:           mgr.GetHTTPClient(),
+		Scheme:               mgr.GetScheme(),
+		Mapper:               mgr.GetRESTMapper(),
+		DefaultLabelSelector: labels.NewSelector().Add(*appliedRequirement),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create cache of applied objects: %w", err)
+	}
+	if err := mgr.Add(appliedCache); err != nil {
+		return err
+	}
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&securityv1alpha1.OrganizationTemplate{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&securityv1alpha1.Organization{}, handler.EnqueueRequestsFromMapFunc(r.organizationToTemplates),
+			builder.WithPredicates(organizationSelectionPredicate())).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	r.appliedCache = appliedCache
+	return nil
+}
@@ -0,0 +1,406 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// recordingController records the sources it is asked to watch.
+type recordingController struct {
+	controller.Controller
+	sources []source.Source
+}
+
+func (c *recordingController) Watch(src source.Source) error {
+	c.sources = append(c.sources, src)
+	return nil
+}
+
+var _ = Describe("OrganizationTemplate Controller", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		reconciler *OrganizationTemplateReconciler
+	)
+
+	reconcileTemplate := func(name string) error {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name},
+		})
+		return err
+	}
+
+	getTemplateStatus := func(name string) securityv1alpha1.OrganizationTemplateStatus {
+		orgTemplate := &securityv1alpha1.OrganizationTemplate{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: name}, orgTemplate)).To(Succeed())
+		return orgTemplate.Status
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("When a template selects organizations", func() {
+		BeforeEach(func() {
+			fakeClient = newFakeClient(
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "acme", Labels: map[string]string{"tier": "gold"}},
+					Spec:       securityv1alpha1.OrganizationSpec{DisplayName: "Acme Corp"},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-acme"},
+				},
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "pending", Labels: map[string]string{"tier": "gold"}},
+				},
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "paused", Labels: map[string]string{"tier": "gold"}},
+					Spec:       securityv1alpha1.OrganizationSpec{Suspended: true},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-paused"},
+				},
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{"tier": "silver"}},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-other"},
+				},
+				&securityv1alpha1.OrganizationTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "baseline"},
+					Spec: securityv1alpha1.OrganizationTemplateSpec{
+						OrganizationSelector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}},
+						Resources: []runtime.RawExtension{
+							{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"organization-info"},` +
+								`"data":{"name":"{{ .Spec.DisplayName }}","namespace":"{{ .Namespace }}"}}`)},
+							{Raw: []byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"{{ .Name }}-ci"}}`)},
+						},
+					},
+				},
+			)
+			reconciler = &OrganizationTemplateReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+			Expect(reconcileTemplate("baseline")).To(Succeed())
+		})
+
+		It("Should apply the rendered objects into the namespaces of the selected organizations", func() {
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "organization-info"}, configMap)).To(Succeed())
+			Expect(configMap.Data).To(Equal(map[string]string{"name": "Acme Corp", "namespace": "org-acme"}))
+			Expect(configMap.Labels).To(HaveKeyWithValue("organization.giantswarm.io/template", "baseline"))
+			Expect(configMap.Labels).To(HaveKeyWithValue("giantswarm.io/organization", "acme"))
+			Expect(metav1.GetControllerOf(configMap).Name).To(Equal("baseline"))
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "acme-ci"}, &corev1.ServiceAccount{})).To(Succeed())
+
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-other", Name: "organization-info"}, &corev1.ConfigMap{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should record the inventory of the applied objects", func() {
+			status := getTemplateStatus("baseline")
+			Expect(status.Selected).To(Equal(int32(3)))
+			Expect(status.Applied).To(Equal(int32(1)))
+			Expect(status.Organizations).To(HaveLen(3))
+			Expect(status.Organizations[0].Name).To(Equal("acme"))
+			Expect(status.Organizations[0].Applied).To(BeTrue())
+			Expect(status.Organizations[0].Inventory).To(ConsistOf(
+				securityv1alpha1.TemplateObjectReference{APIVersion: "v1", Kind: "ConfigMap", Name: "organization-info"},
+				securityv1alpha1.TemplateObjectReference{APIVersion: "v1", Kind: "ServiceAccount", Name: "acme-ci"},
+			))
+		})
+
+		It("Should report the organizations it is not applied to yet", func() {
+			status := getTemplateStatus("baseline")
+			Expect(status.Organizations[2].Name).To(Equal("pending"))
+			Expect(status.Organizations[2].Applied).To(BeFalse())
+			Expect(status.Organizations[2].Message).To(Equal("Waiting for the organization namespace"))
+
+			ready := meta.FindStatusCondition(status.Conditions, securityv1alpha1.TemplateReadyCondition)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("TemplateApplyFailed"))
+			Expect(ready.Message).To(ContainSubstring("pending"))
+		})
+
+		It("Should skip suspended organizations", func() {
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-paused", Name: "organization-info"}, &corev1.ConfigMap{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			status := getTemplateStatus("baseline")
+			Expect(status.Organizations[1].Name).To(Equal("paused"))
+			Expect(status.Organizations[1].Message).To(Equal("Organization is suspended"))
+		})
+
+		It("Should apply the template once a suspended organization is unsuspended", func() {
+			organization := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "paused"}, organization)).To(Succeed())
+			organization.Spec.Suspended = false
+			Expect(fakeClient.Update(ctx, organization)).To(Succeed())
+			Expect(reconcileTemplate("baseline")).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-paused", Name: "organization-info"}, &corev1.ConfigMap{})).To(Succeed())
+			status := getTemplateStatus("baseline")
+			Expect(status.Organizations[1].Name).To(Equal("paused"))
+			Expect(status.Organizations[1].Applied).To(BeTrue())
+		})
+
+		It("Should keep the objects of an organization while it is suspended", func() {
+			organization := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "acme"}, organization)).To(Succeed())
+			organization.Spec.Suspended = true
+			Expect(fakeClient.Update(ctx, organization)).To(Succeed())
+
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "organization-info"}, configMap)).To(Succeed())
+			configMap.Data["name"] = "changed"
+			Expect(fakeClient.Update(ctx, configMap)).To(Succeed())
+			Expect(reconcileTemplate("baseline")).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "organization-info"}, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("name", "changed"))
+			status := getTemplateStatus("baseline")
+			Expect(status.Organizations[0].Applied).To(BeTrue())
+			Expect(status.Organizations[0].Inventory).To(HaveLen(2))
+		})
+
+		It("Should repair drift of the applied objects", func() {
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "organization-info"}, configMap)).To(Succeed())
+			configMap.Data["name"] = "changed"
+			Expect(fakeClient.Update(ctx, configMap)).To(Succeed())
+			Expect(reconcileTemplate("baseline")).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "organization-info"}, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("name", "Acme Corp"))
+		})
+
+		It("Should prune objects removed from the template", func() {
+			orgTemplate := &securityv1alpha1.OrganizationTemplate{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "baseline"}, orgTemplate)).To(Succeed())
+			orgTemplate.Spec.Resources = orgTemplate.Spec.Resources[:1]
+			Expect(fakeClient.Update(ctx, orgTemplate)).To(Succeed())
+			Expect(reconcileTemplate("baseline")).To(Succeed())
+
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "acme-ci"}, &corev1.ServiceAccount{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(getTemplateStatus("baseline").Organizations[0].Inventory).To(ConsistOf(
+				securityv1alpha1.TemplateObjectReference{APIVersion: "v1", Kind: "ConfigMap", Name: "organization-info"},
+			))
+		})
+
+		It("Should not prune objects it does not control", func() {
+			Expect(fakeClient.Delete(ctx, &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "acme-ci"},
+			})).To(Succeed())
+			Expect(fakeClient.Create(ctx, &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "org-acme",
+					Name:      "acme-ci",
+					Labels:    map[string]string{securityv1alpha1.TemplateLabel: "baseline"},
+				},
+			})).To(Succeed())
+
+			orgTemplate := &securityv1alpha1.OrganizationTemplate{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "baseline"}, orgTemplate)).To(Succeed())
+			orgTemplate.Spec.Resources = orgTemplate.Spec.Resources[:1]
+			Expect(fakeClient.Update(ctx, orgTemplate)).To(Succeed())
+			Expect(reconcileTemplate("baseline")).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "acme-ci"}, &corev1.ServiceAccount{})).To(Succeed())
+		})
+
+		It("Should prune the objects of organizations no longer selected", func() {
+			organization := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "acme"}, organization)).To(Succeed())
+			organization.Labels["tier"] = "silver"
+			Expect(fakeClient.Update(ctx, organization)).To(Succeed())
+			Expect(reconcileTemplate("baseline")).To(Succeed())
+
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "organization-info"}, &corev1.ConfigMap{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			status := getTemplateStatus("baseline")
+			Expect(status.Organizations).To(HaveLen(2))
+			Expect(status.Organizations[0].Name).To(Equal("paused"))
+			Expect(status.Organizations[1].Name).To(Equal("pending"))
+		})
+	})
+
+	Context("When a template cannot be applied", func() {
+		BeforeEach(func() {
+			fakeClient = newFakeClient(
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "acme"},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-acme"},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "settings"},
+					Data:       map[string]string{"owner": "someone-else"},
+				},
+			)
+			reconciler = &OrganizationTemplateReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+		})
+
+		createTemplate := func(selector metav1.LabelSelector, manifest string) {
+			Expect(fakeClient.Create(ctx, &securityv1alpha1.OrganizationTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "settings"},
+				Spec: securityv1alpha1.OrganizationTemplateSpec{
+					OrganizationSelector: selector,
+					Resources:            []runtime.RawExtension{{Raw: []byte(manifest)}},
+				},
+			})).To(Succeed())
+		}
+
+		It("Should not take over objects it does not manage", func() {
+			createTemplate(metav1.LabelSelector{}, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"owner":"template"}}`)
+			Expect(reconcileTemplate("settings")).To(Succeed())
+
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "settings"}, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("owner", "someone-else"))
+			Expect(metav1.GetControllerOf(configMap)).To(BeNil())
+
+			status := getTemplateStatus("settings")
+			Expect(status.Organizations).To(HaveLen(1))
+			Expect(status.Organizations[0].Applied).To(BeFalse())
+			Expect(status.Organizations[0].Message).To(ContainSubstring("is not managed by the template"))
+			Expect(status.Organizations[0].Inventory).To(BeEmpty())
+		})
+
+		It("Should not take over objects carrying its label without being controlled by it", func() {
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "settings"}, configMap)).To(Succeed())
+			configMap.Labels = map[string]string{securityv1alpha1.TemplateLabel: "settings"}
+			Expect(fakeClient.Update(ctx, configMap)).To(Succeed())
+
+			createTemplate(metav1.LabelSelector{}, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"owner":"template"}}`)
+			Expect(reconcileTemplate("settings")).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "settings"}, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("owner", "someone-else"))
+			Expect(metav1.GetControllerOf(configMap)).To(BeNil())
+			Expect(getTemplateStatus("settings").Organizations[0].Message).To(ContainSubstring("is not managed by the template"))
+		})
+
+		It("Should refuse objects that are not namespaced", func() {
+			createTemplate(metav1.LabelSelector{}, `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"extra"}}`)
+			Expect(reconcileTemplate("settings")).To(Succeed())
+
+			err := fakeClient.Get(ctx, client.ObjectKey{Name: "extra"}, &corev1.Namespace{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			status := getTemplateStatus("settings")
+			Expect(status.Organizations).To(HaveLen(1))
+			Expect(status.Organizations[0].Applied).To(BeFalse())
+			Expect(status.Organizations[0].Message).To(ContainSubstring("Namespace extra is not namespaced"))
+		})
+
+		It("Should report a rejected organization selector without applying anything", func() {
+			createTemplate(metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key: "tier", Operator: "Matches", Values: []string{"gold"},
+			}}}, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"info"}}`)
+			Expect(reconcileTemplate("settings")).To(Succeed())
+
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "info"}, &corev1.ConfigMap{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			status := getTemplateStatus("settings")
+			Expect(status.Organizations).To(BeEmpty())
+			ready := meta.FindStatusCondition(status.Conditions, securityv1alpha1.TemplateReadyCondition)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("InvalidOrganizationSelector"))
+		})
+	})
+
+	Context("When watching the applied objects", func() {
+		It("Should watch each applied kind once", func() {
+			fakeClient = newFakeClient(
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "acme"},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-acme"},
+				},
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "globex"},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-globex"},
+				},
+				&securityv1alpha1.OrganizationTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "baseline"},
+					Spec: securityv1alpha1.OrganizationTemplateSpec{
+						Resources: []runtime.RawExtension{
+							{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"info"}}`)},
+							{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"}}`)},
+							{Raw: []byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"ci"}}`)},
+						},
+					},
+				},
+			)
+			recorder := &recordingController{}
+			reconciler = &OrganizationTemplateReconciler{
+				Client:     fakeClient,
+				Scheme:     fakeClient.Scheme(),
+				controller: recorder,
+			}
+			Expect(reconcileTemplate("baseline")).To(Succeed())
+			Expect(reconcileTemplate("baseline")).To(Succeed())
+			Expect(recorder.sources).To(HaveLen(2))
+		})
+
+		It("Should map applied objects to the template that applied them", func() {
+			applied := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "org-acme",
+				Name:      "info",
+				Labels:    map[string]string{"organization.giantswarm.io/template": "baseline"},
+			}}
+			Expect(appliedObjectToTemplate(ctx, applied)).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "baseline"}},
+			}))
+			Expect(appliedObjectToTemplate(ctx, &corev1.ConfigMap{})).To(BeEmpty())
+		})
+
+		It("Should only let through updates that may change the declared state", func() {
+			oldDeployment := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "app", Generation: 1}}
+			statusUpdate := oldDeployment.DeepCopy()
+			statusUpdate.ResourceVersion = "2"
+			specUpdate := oldDeployment.DeepCopy()
+			specUpdate.Generation = 2
+			labelUpdate := oldDeployment.DeepCopy()
+			labelUpdate.Labels = map[string]string{"example.com/team": "a"}
+			configMap := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "info"}}
+
+			appliedPredicate := appliedObjectPredicate()
+			Expect(appliedPredicate.Update(event.UpdateEvent{ObjectOld: oldDeployment, ObjectNew: statusUpdate})).To(BeFalse())
+			Expect(appliedPredicate.Update(event.UpdateEvent{ObjectOld: oldDeployment, ObjectNew: specUpdate})).To(BeTrue())
+			Expect(appliedPredicate.Update(event.UpdateEvent{ObjectOld: oldDeployment, ObjectNew: labelUpdate})).To(BeTrue())
+			Expect(appliedPredicate.Update(event.UpdateEvent{ObjectOld: configMap, ObjectNew: configMap.DeepCopy()})).To(BeTrue())
+			Expect(appliedPredicate.Delete(event.DeleteEvent{Object: configMap})).To(BeTrue())
+		})
+	})
+})
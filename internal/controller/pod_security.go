@@ -0,0 +1,239 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+const (
+	podSecurityEnforceLabel        = securityv1alpha1.PodSecurityLabelPrefix + "enforce"
+	podSecurityEnforceVersionLabel = securityv1alpha1.PodSecurityLabelPrefix + "enforce-version"
+
+	// podSecurityRecheckInterval is the interval at which a held back enforce
+	// level is checked again against the existing pods.
+	podSecurityRecheckInterval = 5 * time.Minute
+)
+
+// podSecurityLevelRanks orders the Pod Security levels from the least to the
+// most restrictive. Namespaces without enforce label are privileged.
+var podSecurityLevelRanks = map[string]int{
+	"": 0,
+	string(securityv1alpha1.PodSecurityLevelPrivileged): 0,
+	string(securityv1alpha1.PodSecurityLevelBaseline):   1,
+	string(securityv1alpha1.PodSecurityLevelRestricted): 2,
+}
+
+// PodSecurityDryRunner dry-runs Pod Security labels on a namespace and
+// returns the warnings of the API server about existing pods violating them.
+type PodSecurityDryRunner interface {
+	DryRunNamespaceLabels(ctx context.Context, namespace string, labels map[string]string) ([]string, error)
+}
+
+// NewPodSecurityDryRunner returns a PodSecurityDryRunner using the given config.
+func NewPodSecurityDryRunner(config *rest.Config) PodSecurityDryRunner {
+	return &podSecurityDryRunner{config: config}
+}
+
+type podSecurityDryRunner struct {
+	config *rest.Config
+}
+
+// DryRunNamespaceLabels merge patches the labels onto the namespace in dry-run
+// mode. A dedicated client collects the warnings, as the warning handler is
+// configured per client.
+func (d *podSecurityDryRunner) DryRunNamespaceLabels(ctx context.Context, namespace string, labels map[string]string) ([]string, error) {
+	warnings := &warningRecorder{}
+	config := rest.CopyConfig(d.config)
+	config.WarningHandler = warnings
+	coreClient, err := corev1client.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	patch, err := json.Marshal(map[string]any{"metadata": map[string]any{"labels": labels}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	_, err = coreClient.Namespaces().Patch(ctx, namespace, types.MergePatchType, patch, metav1.PatchOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		FieldManager: fieldManager,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dry-run Namespace %s: %w", namespace, err)
+	}
+	return warnings.messages, nil
+}
+
+// warningRecorder records the warnings returned by the API server.
+type warningRecorder struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (w *warningRecorder) HandleWarningHeader(code int, _ string, message string) {
+	if code != 299 || message == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, message)
+}
+
+// ParsePodSecurity parses a comma separated list of key=value pairs, with
+// keys enforce, enforce-version, audit, audit-version, warn and warn-version.
+func ParsePodSecurity(text string) (securityv1alpha1.PodSecurity, error) {
+	var podSecurity securityv1alpha1.PodSecurity
+	for _, pair := range strings.Split(text, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return podSecurity, fmt.Errorf("pod security setting %q must have the form key=value", pair)
+		}
+
+		switch key {
+		case "enforce", "audit", "warn":
+			if _, ok := podSecurityLevelRanks[value]; !ok {
+				return podSecurity, fmt.Errorf("pod security level %q is unknown", value)
+			}
+		}
+		switch key {
+		case "enforce":
+			podSecurity.Enforce = securityv1alpha1.PodSecurityLevel(value)
+		case "enforce-version":
+			podSecurity.EnforceVersion = value
+		case "audit":
+			podSecurity.Audit = securityv1alpha1.PodSecurityLevel(value)
+		case "audit-version":
+			podSecurity.AuditVersion = value
+		case "warn":
+			podSecurity.Warn = securityv1alpha1.PodSecurityLevel(value)
+		case "warn-version":
+			podSecurity.WarnVersion = value
+		default:
+			return podSecurity, fmt.Errorf("pod security setting %q is unknown", key)
+		}
+	}
+	return podSecurity, nil
+}
+
+// effectivePodSecurity overlays the Pod Security settings of the
+// organization onto the operator defaults.
+func (r *OrganizationReconciler) effectivePodSecurity(organization *securityv1alpha1.Organization) securityv1alpha1.PodSecurity {
+	podSecurity := r.PodSecurityDefaults
+	if spec := organization.Spec.PodSecurity; spec != nil {
+		if spec.Enforce != "" {
+			podSecurity.Enforce = spec.Enforce
+		}
+		if spec.EnforceVersion != "" {
+			podSecurity.EnforceVersion = spec.EnforceVersion
+		}
+		if spec.Audit != "" {
+			podSecurity.Audit = spec.Audit
+		}
+		if spec.AuditVersion != "" {
+			podSecurity.AuditVersion = spec.AuditVersion
+		}
+		if spec.Warn != "" {
+			podSecurity.Warn = spec.Warn
+		}
+		if spec.WarnVersion != "" {
+			podSecurity.WarnVersion = spec.WarnVersion
+		}
+	}
+	return podSecurity
+}
+
+// podSecurityLabels returns the Pod Security Admission labels of the
+// organization namespace. When the enforce level is tightened on an existing
+// namespace, the new level is dry-run first, and held back as long as the API
+// server reports existing pods violating it. The outcome is recorded in the
+// PodSecurityReady condition.
+func (r *OrganizationReconciler) podSecurityLabels(ctx context.Context, organization *securityv1alpha1.Organization, current *corev1.Namespace) (map[string]string, error) {
+	podSecurity := r.effectivePodSecurity(organization)
+	labels := map[string]string{}
+	for key, value := range map[string]string{
+		"enforce":         string(podSecurity.Enforce),
+		"enforce-version": podSecurity.EnforceVersion,
+		"audit":           string(podSecurity.Audit),
+		"audit-version":   podSecurity.AuditVersion,
+		"warn":            string(podSecurity.Warn),
+		"warn-version":    podSecurity.WarnVersion,
+	} {
+		if value != "" {
+			labels[securityv1alpha1.PodSecurityLabelPrefix+key] = value
+		}
+	}
+
+	if current == nil || r.PodSecurityDryRunner == nil ||
+		podSecurityLevelRanks[labels[podSecurityEnforceLabel]] <= podSecurityLevelRanks[current.Labels[podSecurityEnforceLabel]] {
+		setCondition(organization, securityv1alpha1.PodSecurityReadyCondition, metav1.ConditionTrue, reasonPodSecurityReconciled,
+			"Pod Security Admission labels are reconciled")
+		return labels, nil
+	}
+
+	dryRunLabels := map[string]string{podSecurityEnforceLabel: labels[podSecurityEnforceLabel]}
+	if version, ok := labels[podSecurityEnforceVersionLabel]; ok {
+		dryRunLabels[podSecurityEnforceVersionLabel] = version
+	}
+	warnings, err := r.PodSecurityDryRunner.DryRunNamespaceLabels(ctx, current.Name, dryRunLabels)
+	if err != nil {
+		return nil, err
+	}
+	if len(warnings) == 0 {
+		setCondition(organization, securityv1alpha1.PodSecurityReadyCondition, metav1.ConditionTrue, reasonPodSecurityReconciled,
+			"Pod Security Admission labels are reconciled")
+		return labels, nil
+	}
+
+	// Keep enforcing the current level until the violations are resolved.
+	for _, key := range []string{podSecurityEnforceLabel, podSecurityEnforceVersionLabel} {
+		if value, ok := current.Labels[key]; ok {
+			labels[key] = value
+		} else {
+			delete(labels, key)
+		}
+	}
+	setCondition(organization, securityv1alpha1.PodSecurityReadyCondition, metav1.ConditionFalse, reasonPodSecurityViolations,
+		fmt.Sprintf("Enforce level %s is held back because existing pods violate it: %s",
+			podSecurity.Enforce, strings.Join(warnings, "; ")))
+	return labels, nil
+}
+
+// podSecurityHeldBack reports whether a stricter enforce level is held back
+// for the organization namespace.
+func podSecurityHeldBack(organization *securityv1alpha1.Organization) bool {
+	condition := meta.FindStatusCondition(organization.Status.Conditions, securityv1alpha1.PodSecurityReadyCondition)
+	return condition != nil && condition.Reason == reasonPodSecurityViolations
+}
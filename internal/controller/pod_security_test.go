@@ -0,0 +1,206 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// fakePodSecurityDryRunner returns the configured warnings or error and records the dry-run labels.
+type fakePodSecurityDryRunner struct {
+	warnings []string
+	err      error
+	labels   []map[string]string
+}
+
+func (f *fakePodSecurityDryRunner) DryRunNamespaceLabels(_ context.Context, _ string, labels map[string]string) ([]string, error) {
+	f.labels = append(f.labels, labels)
+	return f.warnings, f.err
+}
+
+var _ = Describe("Organization pod security", func() {
+	DescribeTable("Parsing pod security defaults",
+		func(text string, expected *securityv1alpha1.PodSecurity) {
+			podSecurity, err := ParsePodSecurity(text)
+			if expected == nil {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(podSecurity).To(Equal(*expected))
+			}
+		},
+		Entry("empty", "", &securityv1alpha1.PodSecurity{}),
+		Entry("levels and versions", "enforce=baseline,enforce-version=v1.30,warn=restricted", &securityv1alpha1.PodSecurity{
+			Enforce:        securityv1alpha1.PodSecurityLevelBaseline,
+			EnforceVersion: "v1.30",
+			Warn:           securityv1alpha1.PodSecurityLevelRestricted,
+		}),
+		Entry("unknown level", "enforce=strict", nil),
+		Entry("unknown key", "deny=baseline", nil),
+	)
+
+	Context("When reconciling the Pod Security Admission labels", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			dryRunner  *fakePodSecurityDryRunner
+			reconciler *OrganizationReconciler
+		)
+
+		reconcileOrganization := func() (reconcile.Result, error) {
+			return reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-pod-security"},
+			})
+		}
+
+		namespaceLabels := func() map[string]string {
+			namespace := &corev1.Namespace{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "org-test-pod-security"}, namespace)).To(Succeed())
+			return namespace.Labels
+		}
+
+		findCondition := func(conditionType string) *metav1.Condition {
+			org := &securityv1alpha1.Organization{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "test-pod-security"}, org)).To(Succeed())
+			return meta.FindStatusCondition(org.Status.Conditions, conditionType)
+		}
+
+		updatePodSecurity := func(podSecurity *securityv1alpha1.PodSecurity) {
+			org := &securityv1alpha1.Organization{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "test-pod-security"}, org)).To(Succeed())
+			org.Spec.PodSecurity = podSecurity
+			ExpectWithOffset(1, fakeClient.Update(ctx, org)).To(Succeed())
+		}
+
+		restricted := &securityv1alpha1.PodSecurity{
+			Enforce:        securityv1alpha1.PodSecurityLevelRestricted,
+			EnforceVersion: "latest",
+			Audit:          securityv1alpha1.PodSecurityLevelRestricted,
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			fakeClient = newFakeClient()
+			dryRunner = &fakePodSecurityDryRunner{}
+			reconciler = &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+				PodSecurityDefaults: securityv1alpha1.PodSecurity{
+					Enforce: securityv1alpha1.PodSecurityLevelBaseline,
+					Warn:    securityv1alpha1.PodSecurityLevelRestricted,
+				},
+				PodSecurityDryRunner: dryRunner,
+			}
+
+			Expect(fakeClient.Create(ctx, &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-pod-security"},
+			})).To(Succeed())
+			_, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should label a new Namespace with the defaults without a dry run", func() {
+			Expect(namespaceLabels()).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "baseline"))
+			Expect(namespaceLabels()).To(HaveKeyWithValue("pod-security.kubernetes.io/warn", "restricted"))
+			Expect(dryRunner.labels).To(BeEmpty())
+
+			condition := findCondition(securityv1alpha1.PodSecurityReadyCondition)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("PodSecurityReconciled"))
+		})
+
+		It("Should hold the enforce level back while existing pods violate it", func() {
+			dryRunner.warnings = []string{`existing pods in namespace "org-test-pod-security" violate the new PodSecurity enforce level "restricted:latest"`}
+			updatePodSecurity(restricted)
+			result, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.RequeueAfter).To(Equal(podSecurityRecheckInterval))
+			Expect(dryRunner.labels).To(ConsistOf(map[string]string{
+				"pod-security.kubernetes.io/enforce":         "restricted",
+				"pod-security.kubernetes.io/enforce-version": "latest",
+			}))
+			Expect(namespaceLabels()).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "baseline"))
+			Expect(namespaceLabels()).NotTo(HaveKey("pod-security.kubernetes.io/enforce-version"))
+			Expect(namespaceLabels()).To(HaveKeyWithValue("pod-security.kubernetes.io/audit", "restricted"))
+
+			condition := findCondition(securityv1alpha1.PodSecurityReadyCondition)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("PodSecurityViolations"))
+			Expect(condition.Message).To(ContainSubstring("violate the new PodSecurity enforce level"))
+		})
+
+		It("Should enforce the level once the violations are resolved", func() {
+			dryRunner.warnings = []string{"existing pods violate the new PodSecurity enforce level"}
+			updatePodSecurity(restricted)
+			_, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+
+			dryRunner.warnings = nil
+			result, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.RequeueAfter).To(BeNumerically(">=", DefaultResyncPeriod))
+			Expect(namespaceLabels()).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "restricted"))
+			Expect(namespaceLabels()).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce-version", "latest"))
+
+			condition := findCondition(securityv1alpha1.PodSecurityReadyCondition)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("Should loosen the enforce level without a dry run", func() {
+			updatePodSecurity(&securityv1alpha1.PodSecurity{Enforce: securityv1alpha1.PodSecurityLevelPrivileged})
+			_, err := reconcileOrganization()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dryRunner.labels).To(BeEmpty())
+			Expect(namespaceLabels()).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "privileged"))
+		})
+
+		It("Should keep the enforce level when the dry run fails", func() {
+			dryRunner.err = errors.New("dry run rejected")
+			updatePodSecurity(restricted)
+			_, err := reconcileOrganization()
+			Expect(err).To(MatchError(ContainSubstring("dry run rejected")))
+
+			Expect(namespaceLabels()).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "baseline"))
+			Expect(namespaceLabels()).NotTo(HaveKey("pod-security.kubernetes.io/audit"))
+
+			condition := findCondition(securityv1alpha1.NamespaceReadyCondition)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("NamespaceReconcileFailed"))
+		})
+	})
+})
@@ -0,0 +1,149 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+const (
+	resourceQuotaName = "organization-quota"
+	limitRangeName    = "organization-limits"
+)
+
+// reconcileQuota maintains the ResourceQuota and the LimitRange of the
+// organization namespace, and mirrors the ResourceQuota status into the
+// organization status. The outcome is recorded in the QuotaReady condition.
+func (r *OrganizationReconciler) reconcileQuota(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	if err := r.ensureResourceQuota(ctx, organization); err != nil {
+		setCondition(organization, securityv1alpha1.QuotaReadyCondition, metav1.ConditionFalse, reasonQuotaReconcileFailed, err.Error())
+		return err
+	}
+	if err := r.ensureLimitRange(ctx, organization); err != nil {
+		setCondition(organization, securityv1alpha1.QuotaReadyCondition, metav1.ConditionFalse, reasonQuotaReconcileFailed, err.Error())
+		return err
+	}
+	if err := r.countNamespaces(ctx, organization); err != nil {
+		setCondition(organization, securityv1alpha1.QuotaReadyCondition, metav1.ConditionFalse, reasonQuotaReconcileFailed, err.Error())
+		return err
+	}
+	if maxNamespaces := organization.Spec.MaxNamespaces; maxNamespaces != nil && organization.Status.Namespaces > *maxNamespaces {
+		setCondition(organization, securityv1alpha1.QuotaReadyCondition, metav1.ConditionFalse, reasonNamespaceLimitExceeded,
+			fmt.Sprintf("%d namespaces are labelled with the organization, exceeding the maximum of %d",
+				organization.Status.Namespaces, *maxNamespaces))
+		return nil
+	}
+
+	setCondition(organization, securityv1alpha1.QuotaReadyCondition, metav1.ConditionTrue, reasonQuotaReconciled,
+		"ResourceQuota and LimitRange are reconciled")
+	return nil
+}
+
+func (r *OrganizationReconciler) ensureResourceQuota(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	resourceQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceQuotaName,
+			Namespace: organization.Status.Namespace,
+		},
+	}
+	if organization.Spec.Quota == nil {
+		organization.Status.Quota = nil
+		return r.deleteObject(ctx, organization, resourceQuota)
+	}
+
+	_, err := r.ensureObject(ctx, resourceQuota, func() error {
+		resourceQuota.SetLabels(childLabels(resourceQuota.GetLabels(), organization))
+		resourceQuota.Spec = *organization.Spec.Quota.DeepCopy()
+		return ctrl.SetControllerReference(organization, resourceQuota, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+
+	organization.Status.Quota = &securityv1alpha1.QuotaStatus{
+		Hard:    resourceQuota.Status.Hard,
+		Used:    resourceQuota.Status.Used,
+		Summary: quotaSummary(resourceQuota.Status),
+	}
+	return nil
+}
+
+func (r *OrganizationReconciler) ensureLimitRange(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      limitRangeName,
+			Namespace: organization.Status.Namespace,
+		},
+	}
+	if len(organization.Spec.Limits) == 0 {
+		return r.deleteObject(ctx, organization, limitRange)
+	}
+
+	_, err := r.ensureObject(ctx, limitRange, func() error {
+		limitRange.SetLabels(childLabels(limitRange.GetLabels(), organization))
+		limitRange.Spec.Limits = make([]corev1.LimitRangeItem, len(organization.Spec.Limits))
+		for i := range organization.Spec.Limits {
+			organization.Spec.Limits[i].DeepCopyInto(&limitRange.Spec.Limits[i])
+		}
+		return ctrl.SetControllerReference(organization, limitRange, r.Scheme)
+	})
+	return err
+}
+
+// countNamespaces counts the namespaces labelled with the organization into
+// its status when the number of namespaces is capped.
+func (r *OrganizationReconciler) countNamespaces(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	if organization.Spec.MaxNamespaces == nil {
+		organization.Status.Namespaces = 0
+		return nil
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaces, client.MatchingLabels{securityv1alpha1.OrganizationLabel: organization.Name}); err != nil {
+		return fmt.Errorf("failed to list Namespaces: %w", err)
+	}
+	organization.Status.Namespaces = int32(len(namespaces.Items))
+	return nil
+}
+
+// quotaSummary lists the usage against the hard limit of each resource, as
+// shown by kubectl get organizations.
+func quotaSummary(status corev1.ResourceQuotaStatus) string {
+	names := make([]string, 0, len(status.Hard))
+	for name := range status.Hard {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	usage := make([]string, 0, len(names))
+	for _, name := range names {
+		hard := status.Hard[corev1.ResourceName(name)]
+		used := status.Used[corev1.ResourceName(name)]
+		usage = append(usage, fmt.Sprintf("%s: %s/%s", name, used.String(), hard.String()))
+	}
+	return strings.Join(usage, ", ")
+}
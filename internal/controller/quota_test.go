@@ -0,0 +1,153 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Organization quota", func() {
+	Context("When an Organization sets a quota and limits", func() {
+		It("Should manage the ResourceQuota and LimitRange and mirror the quota usage", func() {
+			ctx := context.Background()
+			fakeClient := newFakeClient()
+			reconciler := &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: &record.FakeRecorder{},
+			}
+			reconcileOrganization := func() {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "test-quota"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-quota"},
+				Spec: securityv1alpha1.OrganizationSpec{
+					Quota: &corev1.ResourceQuotaSpec{
+						Hard: corev1.ResourceList{
+							corev1.ResourcePods:        resource.MustParse("10"),
+							corev1.ResourceLimitsCPU:   resource.MustParse("4"),
+							corev1.ResourceRequestsCPU: resource.MustParse("2"),
+						},
+					},
+					Limits: []corev1.LimitRangeItem{{
+						Type: corev1.LimitTypeContainer,
+						DefaultRequest: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("100m"),
+						},
+					}},
+				},
+			}
+			Expect(fakeClient.Create(ctx, org)).To(Succeed())
+			reconcileOrganization()
+
+			resourceQuota := &corev1.ResourceQuota{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-quota", Name: "organization-quota"}, resourceQuota)).To(Succeed())
+			Expect(resourceQuota.Spec.Hard).To(HaveLen(3))
+			Expect(metav1.GetControllerOf(resourceQuota)).NotTo(BeNil())
+
+			limitRange := &corev1.LimitRange{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-quota", Name: "organization-limits"}, limitRange)).To(Succeed())
+			Expect(limitRange.Spec.Limits).To(Equal(org.Spec.Limits))
+
+			By("Reporting the quota usage")
+			resourceQuota.Status = corev1.ResourceQuotaStatus{
+				Hard: resourceQuota.Spec.Hard,
+				Used: corev1.ResourceList{
+					corev1.ResourcePods:        resource.MustParse("3"),
+					corev1.ResourceLimitsCPU:   resource.MustParse("1500m"),
+					corev1.ResourceRequestsCPU: resource.MustParse("0"),
+				},
+			}
+			Expect(fakeClient.Update(ctx, resourceQuota)).To(Succeed())
+			reconcileOrganization()
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-quota"}, org)).To(Succeed())
+			Expect(org.Status.Quota).NotTo(BeNil())
+			Expect(org.Status.Quota.Summary).To(Equal("limits.cpu: 1500m/4, pods: 3/10, requests.cpu: 0/2"))
+			Expect(org.Status.Quota.Used).To(HaveKey(corev1.ResourcePods))
+			Expect(meta.IsStatusConditionTrue(org.Status.Conditions, securityv1alpha1.QuotaReadyCondition)).To(BeTrue())
+
+			By("Unsetting the quota and limits")
+			org.Spec.Quota = nil
+			org.Spec.Limits = nil
+			Expect(fakeClient.Update(ctx, org)).To(Succeed())
+			reconcileOrganization()
+
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-quota", Name: "organization-quota"}, resourceQuota)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			err = fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-quota", Name: "organization-limits"}, limitRange)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-quota"}, org)).To(Succeed())
+			Expect(org.Status.Quota).To(BeNil())
+		})
+
+		It("Should record a Warning event when the ResourceQuota cannot be created", func() {
+			ctx := context.Background()
+			fakeClient := interceptor.NewClient(newFakeClient().(client.WithWatch), interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if _, ok := obj.(*corev1.ResourceQuota); ok {
+						return errors.NewForbidden(schema.GroupResource{Resource: "resourcequotas"}, obj.GetName(), nil)
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			})
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			org := &securityv1alpha1.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-quota-failure"},
+				Spec: securityv1alpha1.OrganizationSpec{
+					Quota: &corev1.ResourceQuotaSpec{
+						Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+					},
+				},
+			}
+			Expect(fakeClient.Create(ctx, org)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-quota-failure"},
+			})
+			Expect(err).To(HaveOccurred())
+
+			Expect(recorder.Events).To(Receive(ContainSubstring("Normal NamespaceCreated")))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning QuotaReconcileFailed failed to create ResourceQuota")))
+		})
+	})
+})
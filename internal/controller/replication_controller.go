@@ -0,0 +1,404 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// Event reasons used by the replication controllers.
+const (
+	eventReasonInvalidReplicationSelector = "InvalidReplicationSelector"
+	eventReasonReplicaConflict            = "ReplicaConflict"
+	eventReasonReplicationNotAllowed      = "ReplicationNotAllowed"
+)
+
+// ReplicationReconciler replicates the Secrets or ConfigMaps annotated with
+// the replicate-to annotation into the namespaces of the organizations their
+// selector matches.
+type ReplicationReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// Object is the kind of the replicated objects, either a Secret or a ConfigMap.
+	Object client.Object
+	// SourceNamespaces are the namespaces sources are replicated from.
+	// Annotated objects in any other namespace, including the organization
+	// namespaces, are not replicated.
+	SourceNamespaces []string
+}
+
+// ReplicationCacheOptions scopes the cache of Secrets and ConfigMaps to the
+// replication source namespaces and, in every other namespace, to the objects
+// managed by the operator, such as replicas, instead of caching every Secret
+// and ConfigMap of the cluster.
+func ReplicationCacheOptions(sourceNamespaces []string) map[client.Object]cache.ByObject {
+	managed, _ := labels.NewRequirement(securityv1alpha1.ManagedByLabel, selection.Equals, []string{managedByValue})
+	namespaces := map[string]cache.Config{cache.AllNamespaces: {}}
+	for _, namespace := range sourceNamespaces {
+		namespaces[namespace] = cache.Config{LabelSelector: labels.Everything()}
+	}
+
+	byObject := map[client.Object]cache.ByObject{}
+	for _, object := range []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}} {
+		byObject[object] = cache.ByObject{
+			Namespaces: namespaces,
+			Label:      labels.NewSelector().Add(*managed),
+		}
+	}
+	return byObject
+}
+
+func (r *ReplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	source := r.newObject()
+	err := r.Get(ctx, req.NamespacedName, source)
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get %s: %w", r.kind(), err)
+	}
+
+	// Without source, or once the annotation is removed, every replica is pruned.
+	var namespaces []string
+	if err == nil && source.GetDeletionTimestamp() == nil {
+		selectorText, ok := source.GetAnnotations()[securityv1alpha1.ReplicateToAnnotation]
+		switch {
+		case !ok:
+		case !slices.Contains(r.SourceNamespaces, source.GetNamespace()):
+			// Tenants must not inject objects into other organizations.
+			r.Recorder.Eventf(source, corev1.EventTypeWarning, eventReasonReplicationNotAllowed,
+				"Namespace %s is not a replication source namespace", source.GetNamespace())
+		default:
+			selector, err := labels.Parse(selectorText)
+			if err != nil {
+				// Keep the replicas until the selector is fixed.
+				r.Recorder.Eventf(source, corev1.EventTypeWarning, eventReasonInvalidReplicationSelector,
+					"Invalid organization selector %q: %v", selectorText, err)
+				return ctrl.Result{}, nil
+			}
+			namespaces, err = r.targetNamespaces(ctx, selector, source.GetNamespace())
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	var errs []error
+	for _, namespace := range namespaces {
+		if err := r.ensureReplica(ctx, source, namespace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := r.pruneReplicas(ctx, req.NamespacedName, namespaces); err != nil {
+		errs = append(errs, err)
+	}
+	return ctrl.Result{}, errors.Join(errs...)
+}
+
+// targetNamespaces returns the namespaces of the organizations matching the
+// selector, except the namespace of the source itself.
+func (r *ReplicationReconciler) targetNamespaces(ctx context.Context, selector labels.Selector, sourceNamespace string) ([]string, error) {
+	organizations := &securityv1alpha1.OrganizationList{}
+	if err := r.List(ctx, organizations, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	var namespaces []string
+	for _, organization := range organizations.Items {
+		namespace := organization.Status.Namespace
+		if namespace == "" || namespace == sourceNamespace || organization.GetDeletionTimestamp() != nil {
+			continue
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+	return namespaces, nil
+}
+
+// ensureReplica creates or updates the replica of the source in the
+// namespace. Objects of the same name that are not replicas of the source are
+// left untouched.
+func (r *ReplicationReconciler) ensureReplica(ctx context.Context, source client.Object, namespace string) error {
+	sourceKey := client.ObjectKeyFromObject(source).String()
+	replica := r.newObject()
+	replica.SetName(source.GetName())
+	replica.SetNamespace(namespace)
+
+	err := r.Get(ctx, client.ObjectKeyFromObject(replica), replica)
+	if apierrors.IsNotFound(err) {
+		return r.createReplica(ctx, source, namespace)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s/%s: %w", r.kind(), namespace, replica.GetName(), err)
+	}
+
+	if replica.GetAnnotations()[securityv1alpha1.ReplicatedFromAnnotation] != sourceKey {
+		r.Recorder.Eventf(source, corev1.EventTypeWarning, eventReasonReplicaConflict,
+			"%s %s/%s already exists and is not a replica of %s", r.kind(), namespace, replica.GetName(), sourceKey)
+		return nil
+	}
+
+	// The type of a Secret is immutable, the replica is recreated when it changes.
+	if sourceSecret, ok := source.(*corev1.Secret); ok && sourceSecret.Type != replica.(*corev1.Secret).Type {
+		if err := r.Delete(ctx, replica); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete %s %s/%s: %w", r.kind(), namespace, replica.GetName(), err)
+		}
+		return r.createReplica(ctx, source, namespace)
+	}
+
+	original := replica.DeepCopyObject().(client.Object)
+	r.setReplica(source, replica)
+	if equality.Semantic.DeepEqual(original, replica) {
+		return nil
+	}
+	if err := r.Patch(ctx, replica, client.MergeFrom(original), client.FieldOwner(fieldManager)); err != nil {
+		return fmt.Errorf("failed to patch %s %s/%s: %w", r.kind(), namespace, replica.GetName(), err)
+	}
+	return nil
+}
+
+// createReplica creates the replica of the source in the namespace.
+func (r *ReplicationReconciler) createReplica(ctx context.Context, source client.Object, namespace string) error {
+	replica := r.newObject()
+	replica.SetName(source.GetName())
+	replica.SetNamespace(namespace)
+	r.setReplica(source, replica)
+	err := r.Create(ctx, replica, client.FieldOwner(fieldManager))
+	if apierrors.IsAlreadyExists(err) {
+		// Objects that are not managed by the operator are not cached.
+		r.Recorder.Eventf(source, corev1.EventTypeWarning, eventReasonReplicaConflict,
+			"%s %s/%s already exists and is not a replica of %s", r.kind(), namespace, replica.GetName(), client.ObjectKeyFromObject(source))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %s %s/%s: %w", r.kind(), namespace, replica.GetName(), err)
+	}
+	return nil
+}
+
+// setReplica copies the contents of the source onto the replica and marks it
+// as a replica of the source.
+func (r *ReplicationReconciler) setReplica(source, replica client.Object) {
+	replicaLabels := replica.GetLabels()
+	if replicaLabels == nil {
+		replicaLabels = map[string]string{}
+	}
+	replicaLabels[securityv1alpha1.ManagedByLabel] = managedByValue
+	replicaLabels[securityv1alpha1.ReplicaLabel] = "true"
+	replica.SetLabels(replicaLabels)
+
+	annotations := replica.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[securityv1alpha1.ReplicatedFromAnnotation] = client.ObjectKeyFromObject(source).String()
+	replica.SetAnnotations(annotations)
+
+	switch source := source.(type) {
+	case *corev1.Secret:
+		secret := replica.(*corev1.Secret)
+		secret.Type = source.Type
+		secret.Data = source.DeepCopy().Data
+	case *corev1.ConfigMap:
+		configMap := replica.(*corev1.ConfigMap)
+		configMap.Data = source.DeepCopy().Data
+		configMap.BinaryData = source.DeepCopy().BinaryData
+	}
+}
+
+// pruneReplicas deletes the replicas of the source outside of the given namespaces.
+func (r *ReplicationReconciler) pruneReplicas(ctx context.Context, source types.NamespacedName, namespaces []string) error {
+	replicas, err := r.listReplicas(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, replica := range replicas {
+		if replica.GetAnnotations()[securityv1alpha1.ReplicatedFromAnnotation] != source.String() ||
+			slices.Contains(namespaces, replica.GetNamespace()) {
+			continue
+		}
+		if err := r.Delete(ctx, replica); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s/%s: %w", r.kind(), replica.GetNamespace(), replica.GetName(), err))
+			continue
+		}
+		log.FromContext(ctx).Info("Pruned replica", "namespace", replica.GetNamespace())
+	}
+	return errors.Join(errs...)
+}
+
+// listReplicas lists the replicas of every source of the reconciled kind.
+func (r *ReplicationReconciler) listReplicas(ctx context.Context) ([]client.Object, error) {
+	replicaSelector := client.MatchingLabels{securityv1alpha1.ReplicaLabel: "true"}
+	var replicas []client.Object
+	switch r.Object.(type) {
+	case *corev1.Secret:
+		secrets := &corev1.SecretList{}
+		if err := r.List(ctx, secrets, replicaSelector); err != nil {
+			return nil, fmt.Errorf("failed to list Secrets: %w", err)
+		}
+		for i := range secrets.Items {
+			replicas = append(replicas, &secrets.Items[i])
+		}
+	case *corev1.ConfigMap:
+		configMaps := &corev1.ConfigMapList{}
+		if err := r.List(ctx, configMaps, replicaSelector); err != nil {
+			return nil, fmt.Errorf("failed to list ConfigMaps: %w", err)
+		}
+		for i := range configMaps.Items {
+			replicas = append(replicas, &configMaps.Items[i])
+		}
+	}
+	return replicas, nil
+}
+
+// listSources lists the objects of the reconciled kind carrying the
+// replicate-to annotation in the source namespaces.
+func (r *ReplicationReconciler) listSources(ctx context.Context) ([]client.Object, error) {
+	var objects []client.Object
+	for _, namespace := range r.SourceNamespaces {
+		switch r.Object.(type) {
+		case *corev1.Secret:
+			secrets := &corev1.SecretList{}
+			if err := r.List(ctx, secrets, client.InNamespace(namespace)); err != nil {
+				return nil, fmt.Errorf("failed to list Secrets in namespace %s: %w", namespace, err)
+			}
+			for i := range secrets.Items {
+				objects = append(objects, &secrets.Items[i])
+			}
+		case *corev1.ConfigMap:
+			configMaps := &corev1.ConfigMapList{}
+			if err := r.List(ctx, configMaps, client.InNamespace(namespace)); err != nil {
+				return nil, fmt.Errorf("failed to list ConfigMaps in namespace %s: %w", namespace, err)
+			}
+			for i := range configMaps.Items {
+				objects = append(objects, &configMaps.Items[i])
+			}
+		}
+	}
+
+	var sources []client.Object
+	for _, object := range objects {
+		if _, ok := object.GetAnnotations()[securityv1alpha1.ReplicateToAnnotation]; ok {
+			sources = append(sources, object)
+		}
+	}
+	return sources, nil
+}
+
+// organizationToSources enqueues every source, as their selectors may match
+// or stop matching the organization.
+func (r *ReplicationReconciler) organizationToSources(ctx context.Context, _ client.Object) []reconcile.Request {
+	sources, err := r.listSources(ctx)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list replication sources")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(sources))
+	for _, source := range sources {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(source)})
+	}
+	return requests
+}
+
+// replicaToSource enqueues the source of a replica, so that changed or
+// deleted replicas are repaired.
+func replicaToSource(_ context.Context, object client.Object) []reconcile.Request {
+	namespace, name, ok := strings.Cut(object.GetAnnotations()[securityv1alpha1.ReplicatedFromAnnotation], "/")
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
+// replicationSourcePredicate only lets through the objects that carry the
+// replicate-to annotation, or carried it before the update.
+func replicationSourcePredicate() predicate.Predicate {
+	isSource := func(object client.Object) bool {
+		_, ok := object.GetAnnotations()[securityv1alpha1.ReplicateToAnnotation]
+		return ok
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isSource(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isSource(e.ObjectOld) || isSource(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isSource(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isSource(e.Object)
+		},
+	}
+}
+
+func (r *ReplicationReconciler) newObject() client.Object {
+	object := r.Object.DeepCopyObject().(client.Object)
+	object.SetName("")
+	object.SetNamespace("")
+	return object
+}
+
+// kind returns the kind of the replicated objects for use in messages.
+func (r *ReplicationReconciler) kind() string {
+	gvk, err := apiutil.GVKForObject(r.Object, r.Scheme)
+	if err != nil {
+		return fmt.Sprintf("%T", r.Object)
+	}
+	return gvk.Kind
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(strings.ToLower(r.kind())+"-replication").
+		For(r.newObject(), builder.WithPredicates(replicationSourcePredicate())).
+		Watches(r.newObject(), handler.EnqueueRequestsFromMapFunc(replicaToSource),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				return object.GetLabels()[securityv1alpha1.ReplicaLabel] == "true"
+			}))).
+		Watches(&securityv1alpha1.Organization{}, handler.EnqueueRequestsFromMapFunc(r.organizationToSources),
+			builder.WithPredicates(organizationSelectionPredicate())).
+		Complete(r)
+}
@@ -0,0 +1,347 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Replication Controller", func() {
+	Context("When a Secret is annotated for replication", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			recorder   *record.FakeRecorder
+			reconciler *ReplicationReconciler
+		)
+
+		reconcileSource := func() error {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "giantswarm", Name: "pull-secret"},
+			})
+			return err
+		}
+
+		replicaExists := func(namespace string) bool {
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "pull-secret"}, &corev1.Secret{})
+			if errors.IsNotFound(err) {
+				return false
+			}
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			return true
+		}
+
+		getReplica := func(namespace string) *corev1.Secret {
+			replica := &corev1.Secret{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "pull-secret"}, replica)).To(Succeed())
+			return replica
+		}
+
+		updateSource := func(update func(*corev1.Secret)) {
+			source := &corev1.Secret{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Namespace: "giantswarm", Name: "pull-secret"}, source)).To(Succeed())
+			update(source)
+			ExpectWithOffset(1, fakeClient.Update(ctx, source)).To(Succeed())
+		}
+
+		objects := func() []client.Object {
+			return []client.Object{
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "acme", Labels: map[string]string{"tier": "gold"}},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-acme"},
+				},
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "globex", Labels: map[string]string{"tier": "gold", "giantswarm.io/organization": "globex"}},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-globex"},
+				},
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "initech", Labels: map[string]string{"tier": "free"}},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-initech"},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   "giantswarm",
+						Name:        "pull-secret",
+						Annotations: map[string]string{securityv1alpha1.ReplicateToAnnotation: "tier=gold"},
+					},
+					Type: corev1.SecretTypeDockerConfigJson,
+					Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+				},
+			}
+		}
+
+		newReconciler := func(c client.Client) *ReplicationReconciler {
+			return &ReplicationReconciler{
+				Client:   c,
+				Scheme:   c.Scheme(),
+				Recorder: recorder,
+				Object:   &corev1.Secret{},
+
+				SourceNamespaces: []string{"giantswarm"},
+			}
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			fakeClient = newFakeClient(objects()...)
+			recorder = record.NewFakeRecorder(10)
+			reconciler = newReconciler(fakeClient)
+		})
+
+		Context("When the replicas are created", func() {
+			BeforeEach(func() {
+				Expect(reconcileSource()).To(Succeed())
+			})
+
+			It("Should replicate the Secret to the matching organizations", func() {
+				replica := getReplica("org-acme")
+				Expect(replica.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
+				Expect(replica.Data).To(HaveKeyWithValue(corev1.DockerConfigJsonKey, []byte(`{"auths":{}}`)))
+				Expect(replica.Labels).To(HaveKeyWithValue(securityv1alpha1.ReplicaLabel, "true"))
+				Expect(replica.Annotations).To(HaveKeyWithValue(securityv1alpha1.ReplicatedFromAnnotation, "giantswarm/pull-secret"))
+				Expect(replica.Annotations).NotTo(HaveKey(securityv1alpha1.ReplicateToAnnotation))
+				Expect(replicaExists("org-globex")).To(BeTrue())
+				Expect(replicaExists("org-initech")).To(BeFalse())
+				Expect(recorder.Events).To(BeEmpty())
+			})
+
+			It("Should update the replicas with the source", func() {
+				updateSource(func(source *corev1.Secret) {
+					source.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths":{"gsoci.azurecr.io":{}}}`)
+				})
+				Expect(reconcileSource()).To(Succeed())
+
+				Expect(getReplica("org-acme").Data).To(HaveKeyWithValue(corev1.DockerConfigJsonKey, []byte(`{"auths":{"gsoci.azurecr.io":{}}}`)))
+				Expect(getReplica("org-globex").Data).To(HaveKeyWithValue(corev1.DockerConfigJsonKey, []byte(`{"auths":{"gsoci.azurecr.io":{}}}`)))
+			})
+
+			It("Should recreate the replicas when the Secret type changes", func() {
+				Expect(fakeClient.Delete(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "giantswarm", Name: "pull-secret"},
+				})).To(Succeed())
+				Expect(fakeClient.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   "giantswarm",
+						Name:        "pull-secret",
+						Annotations: map[string]string{securityv1alpha1.ReplicateToAnnotation: "tier=gold"},
+					},
+					Type: corev1.SecretTypeOpaque,
+					Data: map[string][]byte{"token": []byte("secret")},
+				})).To(Succeed())
+				Expect(reconcileSource()).To(Succeed())
+
+				replica := getReplica("org-acme")
+				Expect(replica.Type).To(Equal(corev1.SecretTypeOpaque))
+				Expect(replica.Data).To(Equal(map[string][]byte{"token": []byte("secret")}))
+			})
+
+			It("Should prune the replicas of organizations no longer matching the selector", func() {
+				updateSource(func(source *corev1.Secret) {
+					source.Annotations[securityv1alpha1.ReplicateToAnnotation] = "tier=gold,giantswarm.io/organization!=globex"
+				})
+				Expect(reconcileSource()).To(Succeed())
+
+				Expect(replicaExists("org-acme")).To(BeTrue())
+				Expect(replicaExists("org-globex")).To(BeFalse())
+			})
+
+			It("Should prune every replica once the annotation is removed", func() {
+				updateSource(func(source *corev1.Secret) {
+					delete(source.Annotations, securityv1alpha1.ReplicateToAnnotation)
+				})
+				Expect(reconcileSource()).To(Succeed())
+
+				Expect(replicaExists("org-acme")).To(BeFalse())
+				Expect(replicaExists("org-globex")).To(BeFalse())
+			})
+
+			It("Should prune every replica once the source is deleted", func() {
+				Expect(fakeClient.Delete(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "giantswarm", Name: "pull-secret"},
+				})).To(Succeed())
+				Expect(reconcileSource()).To(Succeed())
+
+				Expect(replicaExists("org-acme")).To(BeFalse())
+				Expect(replicaExists("org-globex")).To(BeFalse())
+			})
+
+			It("Should keep the replicas while the selector is invalid", func() {
+				updateSource(func(source *corev1.Secret) {
+					source.Annotations[securityv1alpha1.ReplicateToAnnotation] = "tier in gold"
+				})
+				Expect(reconcileSource()).To(Succeed())
+
+				Expect(replicaExists("org-acme")).To(BeTrue())
+				Expect(replicaExists("org-globex")).To(BeTrue())
+				Expect(recorder.Events).To(Receive(ContainSubstring("InvalidReplicationSelector")))
+			})
+		})
+
+		It("Should not replicate Secrets outside of the source namespaces", func() {
+			Expect(fakeClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "org-acme",
+					Name:        "injected",
+					Annotations: map[string]string{securityv1alpha1.ReplicateToAnnotation: ""},
+				},
+				Data: map[string][]byte{"token": []byte("secret")},
+			})).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "org-acme", Name: "injected"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, namespace := range []string{"org-globex", "org-initech"} {
+				err := fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "injected"}, &corev1.Secret{})
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			}
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning ReplicationNotAllowed")))
+		})
+
+		It("Should not enqueue sources outside of the source namespaces", func() {
+			Expect(fakeClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "org-acme",
+					Name:        "injected",
+					Annotations: map[string]string{securityv1alpha1.ReplicateToAnnotation: ""},
+				},
+			})).To(Succeed())
+
+			Expect(reconciler.organizationToSources(ctx, &securityv1alpha1.Organization{})).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "giantswarm", Name: "pull-secret"},
+			}))
+		})
+
+		It("Should keep replicating to the other organizations when a replica cannot be created", func() {
+			reconciler = newReconciler(interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if obj.GetNamespace() == "org-acme" {
+						return fmt.Errorf("create refused")
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}))
+			Expect(reconcileSource()).To(MatchError(ContainSubstring("failed to create Secret org-acme/pull-secret")))
+
+			Expect(replicaExists("org-acme")).To(BeFalse())
+			Expect(replicaExists("org-globex")).To(BeTrue())
+		})
+	})
+
+	Context("When a ConfigMap is annotated for replication", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			recorder   *record.FakeRecorder
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			fakeClient = newFakeClient(
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "acme"},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-acme"},
+				},
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{Name: "globex"},
+					Status:     securityv1alpha1.OrganizationStatus{Namespace: "org-globex"},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "org-globex", Name: "ca-bundle"},
+					Data:       map[string]string{"ca.crt": "globex"},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   "giantswarm",
+						Name:        "ca-bundle",
+						Annotations: map[string]string{securityv1alpha1.ReplicateToAnnotation: ""},
+					},
+					Data: map[string]string{"ca.crt": "platform"},
+				},
+			)
+			recorder = record.NewFakeRecorder(10)
+			reconciler := &ReplicationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: recorder,
+				Object:   &corev1.ConfigMap{},
+
+				SourceNamespaces: []string{"giantswarm"},
+			}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "giantswarm", Name: "ca-bundle"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should replicate the ConfigMap to every organization with an empty selector", func() {
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "ca-bundle"}, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("ca.crt", "platform"))
+		})
+
+		It("Should not overwrite objects that are not replicas and not cached", func() {
+			recorder = record.NewFakeRecorder(10)
+			reconciler := &ReplicationReconciler{
+				Client: interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+					Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+						if key.Namespace == "org-globex" {
+							return errors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+						}
+						return c.Get(ctx, key, obj, opts...)
+					},
+				}),
+				Scheme:   fakeClient.Scheme(),
+				Recorder: recorder,
+				Object:   &corev1.ConfigMap{},
+
+				SourceNamespaces: []string{"giantswarm"},
+			}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "giantswarm", Name: "ca-bundle"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-globex", Name: "ca-bundle"}, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("ca.crt", "globex"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("ReplicaConflict")))
+		})
+
+		It("Should not overwrite objects that are not replicas", func() {
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-globex", Name: "ca-bundle"}, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("ca.crt", "globex"))
+			Expect(configMap.Annotations).NotTo(HaveKey(securityv1alpha1.ReplicatedFromAnnotation))
+			Expect(recorder.Events).To(Receive(ContainSubstring("ReplicaConflict")))
+		})
+	})
+})
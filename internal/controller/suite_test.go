@@ -19,6 +19,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -43,9 +44,18 @@ var _ = BeforeSuite(func() {
 	err := securityv1alpha1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
-	k8sClient = fake.NewClientBuilder().
-		WithScheme(scheme.Scheme).
-		WithStatusSubresource(&securityv1alpha1.Organization{}).
-		Build()
+	k8sClient = newFakeClient()
 	Expect(k8sClient).NotTo(BeNil())
 })
+
+// newFakeClient returns a fake client isolated from the shared k8sClient, so
+// that specs depending on the number of existing organizations are not
+// affected by objects created in other specs.
+func newFakeClient(objects ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)).
+		WithStatusSubresource(&securityv1alpha1.Organization{}, &securityv1alpha1.OrganizationTemplate{}).
+		WithObjects(objects...).
+		Build()
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// reconcileSuspension stops the workloads of a suspended organization,
+// recording their original values, and restores them once the organization is
+// unsuspended. Pods without controller are deleted and not restored. The
+// suspension is recorded in the Suspended condition, which is removed once the
+// workloads are restored.
+func (r *OrganizationReconciler) reconcileSuspension(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	if organization.Spec.Suspended {
+		err := r.scaleWorkloads(ctx, organization, suspendWorkload)
+		if err == nil {
+			err = r.deleteUnownedPods(ctx, organization)
+		}
+		if err != nil {
+			setCondition(organization, securityv1alpha1.SuspendedCondition, metav1.ConditionFalse, reasonSuspensionFailed, err.Error())
+			r.recordFailure(organization, reasonSuspensionFailed, err)
+			return err
+		}
+		setCondition(organization, securityv1alpha1.SuspendedCondition, metav1.ConditionTrue, reasonSuspended,
+			"Workloads are scaled to zero or suspended, pods without controller are deleted and workload changes are denied")
+		return nil
+	}
+
+	if meta.FindStatusCondition(organization.Status.Conditions, securityv1alpha1.SuspendedCondition) == nil {
+		return nil
+	}
+	if err := r.scaleWorkloads(ctx, organization, resumeWorkload); err != nil {
+		setCondition(organization, securityv1alpha1.SuspendedCondition, metav1.ConditionTrue, reasonResumeFailed, err.Error())
+		r.recordFailure(organization, reasonResumeFailed, err)
+		return err
+	}
+	meta.RemoveStatusCondition(&organization.Status.Conditions, securityv1alpha1.SuspendedCondition)
+	return nil
+}
+
+// scaleWorkloads merge patches the workloads of the organization namespace
+// that suspend or resume changes: Deployments, StatefulSets, ReplicaSets
+// without controller and ReplicationControllers are scaled, CronJobs and
+// running Jobs are suspended through spec.suspend and DaemonSets are kept off
+// every node through their node selector. Workloads are read from the API
+// server so that no informers are started for them, which means that workloads
+// scaled up by platform identities while the organization is suspended are
+// only scaled down again on the next resync of the organization, see
+// --resync-period.
+func (r *OrganizationReconciler) scaleWorkloads(ctx context.Context, organization *securityv1alpha1.Organization, scale func(client.Object) error) error {
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+
+	for _, list := range []client.ObjectList{
+		&appsv1.DeploymentList{}, &appsv1.StatefulSetList{}, &appsv1.ReplicaSetList{}, &corev1.ReplicationControllerList{},
+		&appsv1.DaemonSetList{}, &batchv1.CronJobList{}, &batchv1.JobList{},
+	} {
+		if err := reader.List(ctx, list, client.InNamespace(organization.Status.Namespace)); err != nil {
+			return fmt.Errorf("failed to list workloads: %w", err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			workload := item.(client.Object)
+			original := workload.DeepCopyObject().(client.Object)
+			if err := scale(workload); err != nil {
+				return fmt.Errorf("%s %s: %w", r.kindOf(workload), workload.GetName(), err)
+			}
+			if equality.Semantic.DeepEqual(original, workload) {
+				continue
+			}
+			if err := r.Patch(ctx, workload, client.MergeFrom(original), client.FieldOwner(fieldManager)); err != nil {
+				return fmt.Errorf("failed to scale %s %s: %w", r.kindOf(workload), workload.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// suspendWorkload scales the workload to zero or suspends it.
+func suspendWorkload(workload client.Object) error {
+	switch workload := workload.(type) {
+	case *appsv1.Deployment:
+		suspendReplicas(workload, &workload.Spec.Replicas)
+	case *appsv1.StatefulSet:
+		suspendReplicas(workload, &workload.Spec.Replicas)
+	case *appsv1.ReplicaSet:
+		// ReplicaSets of Deployments are scaled by their Deployment.
+		if metav1.GetControllerOf(workload) == nil {
+			suspendReplicas(workload, &workload.Spec.Replicas)
+		}
+	case *corev1.ReplicationController:
+		suspendReplicas(workload, &workload.Spec.Replicas)
+	case *appsv1.DaemonSet:
+		suspendDaemonSet(workload)
+	case *batchv1.CronJob:
+		suspendJob(workload, &workload.Spec.Suspend)
+	case *batchv1.Job:
+		// Finished Jobs have no pods left to stop.
+		if !jobFinished(workload) {
+			suspendJob(workload, &workload.Spec.Suspend)
+		}
+	default:
+		return fmt.Errorf("unsupported workload %T", workload)
+	}
+	return nil
+}
+
+// resumeWorkload restores a workload suspended by suspendWorkload.
+func resumeWorkload(workload client.Object) error {
+	switch workload := workload.(type) {
+	case *appsv1.Deployment:
+		return resumeReplicas(workload, &workload.Spec.Replicas)
+	case *appsv1.StatefulSet:
+		return resumeReplicas(workload, &workload.Spec.Replicas)
+	case *appsv1.ReplicaSet:
+		return resumeReplicas(workload, &workload.Spec.Replicas)
+	case *corev1.ReplicationController:
+		return resumeReplicas(workload, &workload.Spec.Replicas)
+	case *appsv1.DaemonSet:
+		resumeDaemonSet(workload)
+		return nil
+	case *batchv1.CronJob:
+		return resumeJob(workload, &workload.Spec.Suspend)
+	case *batchv1.Job:
+		return resumeJob(workload, &workload.Spec.Suspend)
+	default:
+		return fmt.Errorf("unsupported workload %T", workload)
+	}
+}
+
+// suspendReplicas scales the workload to zero, recording its replicas unless
+// they are recorded already.
+func suspendReplicas(workload client.Object, replicas **int32) {
+	original := int32(1)
+	if *replicas != nil {
+		original = **replicas
+	}
+	recordOriginal(workload, securityv1alpha1.SuspendedReplicasAnnotation, strconv.FormatInt(int64(original), 10))
+	*replicas = ptr.To(int32(0))
+}
+
+// resumeReplicas restores the replicas recorded for a suspended workload.
+func resumeReplicas(workload client.Object, replicas **int32) error {
+	value, ok := workload.GetAnnotations()[securityv1alpha1.SuspendedReplicasAnnotation]
+	if !ok {
+		return nil
+	}
+	original, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation %q: %w", securityv1alpha1.SuspendedReplicasAnnotation, value, err)
+	}
+	*replicas = ptr.To(int32(original))
+	forgetOriginal(workload, securityv1alpha1.SuspendedReplicasAnnotation)
+	return nil
+}
+
+// suspendDaemonSet keeps the pods of the DaemonSet off every node by
+// requiring the suspended label, which no node carries, in its node selector.
+func suspendDaemonSet(daemonSet *appsv1.DaemonSet) {
+	if daemonSet.Spec.Template.Spec.NodeSelector == nil {
+		daemonSet.Spec.Template.Spec.NodeSelector = map[string]string{}
+	}
+	daemonSet.Spec.Template.Spec.NodeSelector[securityv1alpha1.SuspendedLabel] = "true"
+}
+
+// resumeDaemonSet removes the node selector added by suspendDaemonSet.
+func resumeDaemonSet(daemonSet *appsv1.DaemonSet) {
+	delete(daemonSet.Spec.Template.Spec.NodeSelector, securityv1alpha1.SuspendedLabel)
+	if len(daemonSet.Spec.Template.Spec.NodeSelector) == 0 {
+		daemonSet.Spec.Template.Spec.NodeSelector = nil
+	}
+}
+
+// deleteUnownedPods deletes the running pods of the organization namespace
+// that have no controller, as there is no workload to stop them. They are not
+// restored once the organization is unsuspended.
+func (r *OrganizationReconciler) deleteUnownedPods(ctx context.Context, organization *securityv1alpha1.Organization) error {
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+
+	pods := &corev1.PodList{}
+	if err := reader.List(ctx, pods, client.InNamespace(organization.Status.Namespace)); err != nil {
+		return fmt.Errorf("failed to list Pods: %w", err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if metav1.GetControllerOf(pod) != nil || pod.DeletionTimestamp != nil ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete Pod %s: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// suspendJob suspends the Job or CronJob, recording its spec.suspend unless
+// it is recorded already.
+func suspendJob(workload client.Object, suspend **bool) {
+	recordOriginal(workload, securityv1alpha1.OriginalSuspendAnnotation, strconv.FormatBool(ptr.Deref(*suspend, false)))
+	*suspend = ptr.To(true)
+}
+
+// resumeJob restores the spec.suspend recorded for a suspended Job or CronJob.
+func resumeJob(workload client.Object, suspend **bool) error {
+	value, ok := workload.GetAnnotations()[securityv1alpha1.OriginalSuspendAnnotation]
+	if !ok {
+		return nil
+	}
+	original, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation %q: %w", securityv1alpha1.OriginalSuspendAnnotation, value, err)
+	}
+	*suspend = ptr.To(original)
+	forgetOriginal(workload, securityv1alpha1.OriginalSuspendAnnotation)
+	return nil
+}
+
+// jobFinished reports whether the Job completed or failed.
+func jobFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// recordOriginal records the original value of the field changed by the
+// suspension in the annotation, unless it is recorded already.
+func recordOriginal(workload client.Object, annotation, value string) {
+	annotations := workload.GetAnnotations()
+	if _, ok := annotations[annotation]; ok {
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[annotation] = value
+	workload.SetAnnotations(annotations)
+}
+
+// forgetOriginal removes the annotation recording an original value.
+func forgetOriginal(workload client.Object, annotation string) {
+	annotations := workload.GetAnnotations()
+	delete(annotations, annotation)
+	workload.SetAnnotations(annotations)
+}
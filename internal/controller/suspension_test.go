@@ -0,0 +1,330 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Organization suspension", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		reconciler *OrganizationReconciler
+	)
+
+	reconcileOrganization := func() error {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "test-suspended"},
+		})
+		return err
+	}
+
+	getWorkload := func(name string, workload client.Object) {
+		ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-suspended", Name: name}, workload)).To(Succeed())
+	}
+
+	setSuspended := func(suspended bool) {
+		org := &securityv1alpha1.Organization{}
+		ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "test-suspended"}, org)).To(Succeed())
+		org.Spec.Suspended = suspended
+		ExpectWithOffset(1, fakeClient.Update(ctx, org)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fakeClient = newFakeClient(
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "web"},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(3))},
+			},
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "db"},
+			},
+			&batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "nightly"},
+			},
+			&batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "paused"},
+				Spec:       batchv1.CronJobSpec{Suspend: ptr.To(true)},
+			},
+			&batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "running"},
+			},
+			&appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "standalone"},
+				Spec:       appsv1.ReplicaSetSpec{Replicas: ptr.To(int32(2))},
+			},
+			&appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "org-test-suspended",
+					Name:      "web-5d8f",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: ptr.To(true),
+					}},
+				},
+				Spec: appsv1.ReplicaSetSpec{Replicas: ptr.To(int32(3))},
+			},
+			&corev1.ReplicationController{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "legacy"},
+				Spec:       corev1.ReplicationControllerSpec{Replicas: ptr.To(int32(2))},
+			},
+			&appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "agent"},
+				Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+				}}},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "debug"},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "finished"},
+				Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "org-test-suspended",
+					Name:      "web-5d8f-x2k4",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-5d8f", UID: "web-5d8f-uid", Controller: ptr.To(true),
+					}},
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+			&batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-suspended", Name: "done"},
+				Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+				}},
+			},
+		)
+		reconciler = &OrganizationReconciler{
+			Client:   fakeClient,
+			Scheme:   fakeClient.Scheme(),
+			Recorder: &record.FakeRecorder{},
+		}
+
+		Expect(fakeClient.Create(ctx, &securityv1alpha1.Organization{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-suspended"},
+			Spec: securityv1alpha1.OrganizationSpec{
+				Suspended: true,
+				Quota: &corev1.ResourceQuotaSpec{
+					Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+				},
+			},
+		})).To(Succeed())
+		Expect(reconcileOrganization()).To(Succeed())
+	})
+
+	Context("When an Organization is suspended", func() {
+		It("Should label its namespace as suspended", func() {
+			namespace := &corev1.Namespace{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "org-test-suspended"}, namespace)).To(Succeed())
+			Expect(namespace.Labels).To(HaveKeyWithValue(securityv1alpha1.SuspendedLabel, "true"))
+		})
+
+		It("Should scale its Deployments and StatefulSets to zero, recording their replicas", func() {
+			deployment := &appsv1.Deployment{}
+			getWorkload("web", deployment)
+			Expect(*deployment.Spec.Replicas).To(BeZero())
+			Expect(deployment.Annotations).To(HaveKeyWithValue(securityv1alpha1.SuspendedReplicasAnnotation, "3"))
+
+			statefulSet := &appsv1.StatefulSet{}
+			getWorkload("db", statefulSet)
+			Expect(*statefulSet.Spec.Replicas).To(BeZero())
+			Expect(statefulSet.Annotations).To(HaveKeyWithValue(securityv1alpha1.SuspendedReplicasAnnotation, "1"))
+		})
+
+		It("Should scale its ReplicaSets without Deployment and ReplicationControllers to zero", func() {
+			replicaSet := &appsv1.ReplicaSet{}
+			getWorkload("standalone", replicaSet)
+			Expect(*replicaSet.Spec.Replicas).To(BeZero())
+			Expect(replicaSet.Annotations).To(HaveKeyWithValue(securityv1alpha1.SuspendedReplicasAnnotation, "2"))
+			getWorkload("web-5d8f", replicaSet)
+			Expect(*replicaSet.Spec.Replicas).To(Equal(int32(3)))
+			Expect(replicaSet.Annotations).NotTo(HaveKey(securityv1alpha1.SuspendedReplicasAnnotation))
+
+			replicationController := &corev1.ReplicationController{}
+			getWorkload("legacy", replicationController)
+			Expect(*replicationController.Spec.Replicas).To(BeZero())
+			Expect(replicationController.Annotations).To(HaveKeyWithValue(securityv1alpha1.SuspendedReplicasAnnotation, "2"))
+		})
+
+		It("Should keep its DaemonSets off every node", func() {
+			daemonSet := &appsv1.DaemonSet{}
+			getWorkload("agent", daemonSet)
+			Expect(daemonSet.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+				"kubernetes.io/os":              "linux",
+				securityv1alpha1.SuspendedLabel: "true",
+			}))
+		})
+
+		It("Should delete its running pods without controller", func() {
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-suspended", Name: "debug"}, &corev1.Pod{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			getWorkload("finished", &corev1.Pod{})
+			getWorkload("web-5d8f-x2k4", &corev1.Pod{})
+		})
+
+		It("Should suspend its CronJobs and running Jobs, recording their suspend field", func() {
+			cronJob := &batchv1.CronJob{}
+			getWorkload("nightly", cronJob)
+			Expect(cronJob.Spec.Suspend).To(Equal(ptr.To(true)))
+			Expect(cronJob.Annotations).To(HaveKeyWithValue(securityv1alpha1.OriginalSuspendAnnotation, "false"))
+			getWorkload("paused", cronJob)
+			Expect(cronJob.Spec.Suspend).To(Equal(ptr.To(true)))
+			Expect(cronJob.Annotations).To(HaveKeyWithValue(securityv1alpha1.OriginalSuspendAnnotation, "true"))
+
+			job := &batchv1.Job{}
+			getWorkload("running", job)
+			Expect(job.Spec.Suspend).To(Equal(ptr.To(true)))
+			Expect(job.Annotations).To(HaveKeyWithValue(securityv1alpha1.OriginalSuspendAnnotation, "false"))
+			getWorkload("done", job)
+			Expect(job.Spec.Suspend).To(BeNil())
+			Expect(job.Annotations).NotTo(HaveKey(securityv1alpha1.OriginalSuspendAnnotation))
+		})
+
+		It("Should report the suspension and not reconcile the managed objects", func() {
+			err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-suspended", Name: "organization-quota"}, &corev1.ResourceQuota{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-suspended"}, org)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(org.Status.Conditions, securityv1alpha1.SuspendedCondition)).To(BeTrue())
+			ready := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.ReadyCondition)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("Suspended"))
+		})
+
+		It("Should scale workloads down again, keeping the recorded replicas", func() {
+			deployment := &appsv1.Deployment{}
+			getWorkload("web", deployment)
+			deployment.Spec.Replicas = ptr.To(int32(5))
+			Expect(fakeClient.Update(ctx, deployment)).To(Succeed())
+
+			Expect(reconcileOrganization()).To(Succeed())
+			getWorkload("web", deployment)
+			Expect(*deployment.Spec.Replicas).To(BeZero())
+			Expect(deployment.Annotations).To(HaveKeyWithValue(securityv1alpha1.SuspendedReplicasAnnotation, "3"))
+		})
+	})
+
+	Context("When a suspended Organization is unsuspended", func() {
+		BeforeEach(func() {
+			setSuspended(false)
+			Expect(reconcileOrganization()).To(Succeed())
+		})
+
+		It("Should restore the replicas of its Deployments and StatefulSets", func() {
+			deployment := &appsv1.Deployment{}
+			getWorkload("web", deployment)
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+			Expect(deployment.Annotations).NotTo(HaveKey(securityv1alpha1.SuspendedReplicasAnnotation))
+
+			statefulSet := &appsv1.StatefulSet{}
+			getWorkload("db", statefulSet)
+			Expect(*statefulSet.Spec.Replicas).To(Equal(int32(1)))
+		})
+
+		It("Should restore the replicas of its ReplicaSets and ReplicationControllers", func() {
+			replicaSet := &appsv1.ReplicaSet{}
+			getWorkload("standalone", replicaSet)
+			Expect(*replicaSet.Spec.Replicas).To(Equal(int32(2)))
+			Expect(replicaSet.Annotations).NotTo(HaveKey(securityv1alpha1.SuspendedReplicasAnnotation))
+
+			replicationController := &corev1.ReplicationController{}
+			getWorkload("legacy", replicationController)
+			Expect(*replicationController.Spec.Replicas).To(Equal(int32(2)))
+		})
+
+		It("Should restore the node selector of its DaemonSets", func() {
+			daemonSet := &appsv1.DaemonSet{}
+			getWorkload("agent", daemonSet)
+			Expect(daemonSet.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux"}))
+		})
+
+		It("Should restore the suspend field of its CronJobs and Jobs", func() {
+			cronJob := &batchv1.CronJob{}
+			getWorkload("nightly", cronJob)
+			Expect(cronJob.Spec.Suspend).To(Equal(ptr.To(false)))
+			Expect(cronJob.Annotations).NotTo(HaveKey(securityv1alpha1.OriginalSuspendAnnotation))
+			getWorkload("paused", cronJob)
+			Expect(cronJob.Spec.Suspend).To(Equal(ptr.To(true)))
+
+			job := &batchv1.Job{}
+			getWorkload("running", job)
+			Expect(job.Spec.Suspend).To(Equal(ptr.To(false)))
+			Expect(job.Annotations).NotTo(HaveKey(securityv1alpha1.OriginalSuspendAnnotation))
+		})
+
+		It("Should remove the suspension and reconcile the managed objects again", func() {
+			namespace := &corev1.Namespace{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "org-test-suspended"}, namespace)).To(Succeed())
+			Expect(namespace.Labels).NotTo(HaveKey(securityv1alpha1.SuspendedLabel))
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "org-test-suspended", Name: "organization-quota"}, &corev1.ResourceQuota{})).To(Succeed())
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-suspended"}, org)).To(Succeed())
+			Expect(meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.SuspendedCondition)).To(BeNil())
+			Expect(meta.IsStatusConditionTrue(org.Status.Conditions, securityv1alpha1.ReadyCondition)).To(BeTrue())
+		})
+	})
+
+	Context("When the workloads cannot be restored", func() {
+		It("Should keep the organization suspended and report the failure", func() {
+			deployment := &appsv1.Deployment{}
+			getWorkload("web", deployment)
+			deployment.Annotations[securityv1alpha1.SuspendedReplicasAnnotation] = "three"
+			Expect(fakeClient.Update(ctx, deployment)).To(Succeed())
+
+			setSuspended(false)
+			Expect(reconcileOrganization()).To(MatchError(ContainSubstring(`invalid organization.giantswarm.io/suspended-replicas annotation "three"`)))
+
+			org := &securityv1alpha1.Organization{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "test-suspended"}, org)).To(Succeed())
+			suspended := meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.SuspendedCondition)
+			Expect(suspended).NotTo(BeNil())
+			Expect(suspended.Status).To(Equal(metav1.ConditionTrue))
+			Expect(suspended.Reason).To(Equal("ResumeFailed"))
+		})
+
+		It("Should refuse workloads of unsupported kinds", func() {
+			Expect(suspendWorkload(&appsv1.ControllerRevision{})).To(MatchError(ContainSubstring("unsupported workload")))
+			Expect(resumeWorkload(&appsv1.ControllerRevision{})).To(MatchError(ContainSubstring("unsupported workload")))
+		})
+	})
+})
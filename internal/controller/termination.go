@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+const (
+	// DefaultDeletionStuckThreshold is the default time after which a
+	// deletion that has not completed is reported as stuck.
+	DefaultDeletionStuckThreshold = 30 * time.Minute
+	// DefaultDeletionTimeout is the default time after which the operator
+	// stops polling a namespace that is not deleted.
+	DefaultDeletionTimeout = 2 * time.Hour
+
+	// deletionMinBackoff and deletionMaxBackoff bound the delay between the
+	// checks of a terminating namespace.
+	deletionMinBackoff = time.Second
+	deletionMaxBackoff = 5 * time.Minute
+)
+
+var organizationDeletionStuck = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "organization_deletion_stuck",
+		Help: "Whether the deletion of the organization takes longer than the threshold",
+	},
+	[]string{"organization"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(organizationDeletionStuck)
+}
+
+// setTerminationStatus reports the progress of the deletion of the
+// organization in its status: the time spent terminating and, for a
+// namespace being deleted, its remaining content conditions and objects.
+// Deletions taking longer than the threshold are reported as stuck in the
+// organization_deletion_stuck metric and by a DeletionStuck event.
+func (r *OrganizationReconciler) setTerminationStatus(ctx context.Context, organization *securityv1alpha1.Organization, namespace *corev1.Namespace) error {
+	termination := &securityv1alpha1.TerminationStatus{
+		StartedAt: *organization.DeletionTimestamp,
+	}
+	if organization.Status.Termination != nil {
+		termination.Stuck = organization.Status.Termination.Stuck
+	}
+	elapsed := time.Since(termination.StartedAt.Time).Round(time.Second)
+	termination.Duration = metav1.Duration{Duration: elapsed}
+
+	if namespace != nil && namespace.DeletionTimestamp != nil {
+		for _, condition := range namespace.Status.Conditions {
+			if condition.Type == corev1.NamespaceContentRemaining || condition.Type == corev1.NamespaceFinalizersRemaining {
+				termination.NamespaceConditions = append(termination.NamespaceConditions, condition)
+			}
+		}
+		remaining, err := r.inventoryNamespace(ctx, namespace.Name)
+		if err != nil {
+			return err
+		}
+		if len(remaining) > 0 {
+			termination.Remaining = remaining
+		}
+	}
+
+	if elapsed > r.deletionStuckThreshold() {
+		if !termination.Stuck {
+			r.Recorder.Eventf(organization, corev1.EventTypeWarning, eventReasonDeletionStuck,
+				"Deletion has not completed after %s", elapsed)
+		}
+		termination.Stuck = true
+		organizationDeletionStuck.WithLabelValues(organization.Name).Set(1)
+	}
+
+	if namespace != nil && elapsed > r.deletionTimeout() {
+		if !meta.IsStatusConditionTrue(organization.Status.Conditions, securityv1alpha1.DeletionStalledCondition) {
+			r.Recorder.Eventf(organization, corev1.EventTypeWarning, eventReasonDeletionStalled,
+				"Namespace %s is not deleted after %s", namespace.Name, elapsed)
+		}
+		setCondition(organization, securityv1alpha1.DeletionStalledCondition, metav1.ConditionTrue, reasonDeletionTimedOut,
+			fmt.Sprintf("Namespace %s is not deleted after %s, further progress is only picked up from namespace changes",
+				namespace.Name, elapsed))
+	} else {
+		meta.RemoveStatusCondition(&organization.Status.Conditions, securityv1alpha1.DeletionStalledCondition)
+	}
+
+	organization.Status.Termination = termination
+	return nil
+}
+
+// deletionRequeueAfter returns the delay after which a terminating namespace
+// is checked again, in addition to the checks triggered by its changes. The
+// delay grows with the time spent terminating, doubling it with every check,
+// and is capped. Stalled deletions are not checked periodically anymore.
+func (r *OrganizationReconciler) deletionRequeueAfter(organization *securityv1alpha1.Organization) time.Duration {
+	if meta.IsStatusConditionTrue(organization.Status.Conditions, securityv1alpha1.DeletionStalledCondition) {
+		return 0
+	}
+	elapsed := time.Since(organization.DeletionTimestamp.Time)
+	delay := min(max(elapsed, deletionMinBackoff), deletionMaxBackoff)
+	// Check once more right after the timeout to report the stalled deletion.
+	return min(delay, max(r.deletionTimeout()-elapsed, 0)+deletionMinBackoff)
+}
+
+// forgetTermination drops the metrics of the deleted organization.
+func forgetTermination(organization *securityv1alpha1.Organization) {
+	organizationDeletionStuck.DeleteLabelValues(organization.Name)
+}
+
+func (r *OrganizationReconciler) deletionTimeout() time.Duration {
+	if r.DeletionTimeout == 0 {
+		return DefaultDeletionTimeout
+	}
+	return r.DeletionTimeout
+}
+
+func (r *OrganizationReconciler) deletionStuckThreshold() time.Duration {
+	if r.DeletionStuckThreshold == 0 {
+		return DefaultDeletionStuckThreshold
+	}
+	return r.DeletionStuckThreshold
+}
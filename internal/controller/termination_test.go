@@ -0,0 +1,271 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Organization termination", func() {
+	Context("When the Organization Namespace is terminating", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			recorder   *record.FakeRecorder
+			reconciler *OrganizationReconciler
+		)
+
+		reconcileOrganization := func() (reconcile.Result, error) {
+			return reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-terminating"},
+			})
+		}
+
+		getOrganization := func() *securityv1alpha1.Organization {
+			org := &securityv1alpha1.Organization{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "test-terminating"}, org)).To(Succeed())
+			return org
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			deletionTimestamp := metav1.NewTime(time.Now().Add(-time.Hour))
+			fakeClient = newFakeClient(
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-terminating",
+						DeletionTimestamp: &deletionTimestamp,
+						Finalizers:        []string{newFinalizer},
+					},
+					Status: securityv1alpha1.OrganizationStatus{Namespace: "org-test-terminating"},
+				},
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "org-test-terminating",
+						DeletionTimestamp: &deletionTimestamp,
+						Finalizers:        []string{"example.com/cleanup"},
+					},
+					Status: corev1.NamespaceStatus{
+						Phase: corev1.NamespaceTerminating,
+						Conditions: []corev1.NamespaceCondition{
+							{Type: corev1.NamespaceDeletionDiscoveryFailure, Status: corev1.ConditionFalse},
+							{
+								Type:    corev1.NamespaceContentRemaining,
+								Status:  corev1.ConditionTrue,
+								Reason:  "SomeResourcesRemain",
+								Message: "Some resources are remaining: pods. has 2 resource instances",
+							},
+						},
+					},
+				},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-terminating", Name: "web-1"}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "org-test-terminating", Name: "web-2"}},
+			)
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		AfterEach(func() {
+			organizationDeletionStuck.DeleteLabelValues("test-terminating")
+		})
+
+		Context("When the deletion progress is reported", func() {
+			BeforeEach(func() {
+				result, err := reconcileOrganization()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(deletionMaxBackoff))
+			})
+
+			It("Should report the remaining content of the Namespace", func() {
+				org := getOrganization()
+				Expect(org.Status.Phase).To(Equal(securityv1alpha1.OrganizationPhaseTerminating))
+				termination := org.Status.Termination
+				Expect(termination).NotTo(BeNil())
+				Expect(termination.Duration.Duration).To(BeNumerically(">=", time.Hour))
+				Expect(termination.NamespaceConditions).To(HaveLen(1))
+				Expect(termination.NamespaceConditions[0].Type).To(Equal(corev1.NamespaceContentRemaining))
+				Expect(termination.Remaining).To(Equal(map[string]int32{"pods": 2}))
+			})
+
+			It("Should report a stuck deletion once", func() {
+				Expect(getOrganization().Status.Termination.Stuck).To(BeTrue())
+				Expect(recorder.Events).To(Receive(ContainSubstring("Normal DeletionStarted")))
+				Expect(recorder.Events).To(Receive(ContainSubstring("Warning DeletionStuck")))
+				Expect(testutil.ToFloat64(organizationDeletionStuck.WithLabelValues("test-terminating"))).To(Equal(float64(1)))
+
+				_, err := reconcileOrganization()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(recorder.Events).To(BeEmpty())
+			})
+
+			It("Should complete the deletion once the Namespace is deleted", func() {
+				namespace := &corev1.Namespace{}
+				Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "org-test-terminating"}, namespace)).To(Succeed())
+				namespace.Finalizers = nil
+				Expect(fakeClient.Update(ctx, namespace)).To(Succeed())
+				for len(recorder.Events) > 0 {
+					<-recorder.Events
+				}
+				_, err := reconcileOrganization()
+				Expect(err).NotTo(HaveOccurred())
+
+				err = fakeClient.Get(ctx, client.ObjectKey{Name: "test-terminating"}, &securityv1alpha1.Organization{})
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+				Expect(recorder.Events).To(Receive(ContainSubstring("Normal DeletionCompleted")))
+				Expect(testutil.CollectAndCount(organizationDeletionStuck)).To(BeZero())
+			})
+		})
+
+		It("Should keep the finalizer when the remaining content cannot be listed", func() {
+			reconciler.APIReader = interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+				List: func(_ context.Context, _ client.WithWatch, _ client.ObjectList, _ ...client.ListOption) error {
+					return fmt.Errorf("list refused")
+				},
+			})
+			_, err := reconcileOrganization()
+			Expect(err).To(MatchError(ContainSubstring("list refused")))
+
+			Expect(getOrganization().Finalizers).To(ConsistOf(newFinalizer))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Normal DeletionStarted")))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning DeletionFailed")))
+		})
+	})
+
+	Context("When the Organization Namespace is not deleted in time", func() {
+		var (
+			ctx        context.Context
+			fakeClient client.Client
+			recorder   *record.FakeRecorder
+			reconciler *OrganizationReconciler
+		)
+
+		reconcileOrganization := func() reconcile.Result {
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-stalled"},
+			})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			return result
+		}
+
+		stalledCondition := func() *metav1.Condition {
+			org := &securityv1alpha1.Organization{}
+			ExpectWithOffset(1, fakeClient.Get(ctx, client.ObjectKey{Name: "test-stalled"}, org)).To(Succeed())
+			return meta.FindStatusCondition(org.Status.Conditions, securityv1alpha1.DeletionStalledCondition)
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			deletionTimestamp := metav1.NewTime(time.Now().Add(-10 * time.Second))
+			fakeClient = newFakeClient(
+				&securityv1alpha1.Organization{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-stalled",
+						DeletionTimestamp: &deletionTimestamp,
+						Finalizers:        []string{newFinalizer},
+					},
+					Status: securityv1alpha1.OrganizationStatus{Namespace: "org-test-stalled"},
+				},
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "org-test-stalled",
+						Finalizers: []string{"example.com/cleanup"},
+					},
+				},
+			)
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &OrganizationReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: recorder,
+			}
+		})
+
+		It("Should delete the Namespace and back off with the time spent terminating", func() {
+			result := reconcileOrganization()
+			Expect(result.Requeue).To(BeFalse())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 10*time.Second, time.Second))
+
+			namespace := &corev1.Namespace{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "org-test-stalled"}, namespace)).To(Succeed())
+			Expect(namespace.DeletionTimestamp).NotTo(BeNil())
+			Expect(stalledCondition()).To(BeNil())
+		})
+
+		It("Should check once more right after the timeout", func() {
+			reconciler.DeletionTimeout = 12 * time.Second
+			result := reconcileOrganization()
+			Expect(result.RequeueAfter).To(BeNumerically("~", 2*time.Second+deletionMinBackoff, time.Second))
+		})
+
+		It("Should stop polling and report the stalled deletion once the timeout is exceeded", func() {
+			reconciler.DeletionTimeout = 5 * time.Second
+			result := reconcileOrganization()
+			Expect(result.RequeueAfter).To(BeZero())
+
+			stalled := stalledCondition()
+			Expect(stalled).NotTo(BeNil())
+			Expect(stalled.Status).To(Equal(metav1.ConditionTrue))
+			Expect(stalled.Reason).To(Equal("DeletionTimedOut"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Normal DeletionStarted")))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning DeletionStalled")))
+
+			reconcileOrganization()
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+
+	Context("When a terminating Namespace changes", func() {
+		It("Should only let through the progress of its deletion", func() {
+			deletionTimestamp := metav1.Now()
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "org-test"}}
+			terminating := namespace.DeepCopy()
+			terminating.DeletionTimestamp = &deletionTimestamp
+			progressed := terminating.DeepCopy()
+			progressed.Status.Conditions = []corev1.NamespaceCondition{
+				{Type: corev1.NamespaceContentRemaining, Status: corev1.ConditionFalse},
+			}
+			relabelled := progressed.DeepCopy()
+			relabelled.Labels = map[string]string{"example.com/team": "a"}
+
+			Expect(namespaceDeletionProgressed(namespace, terminating)).To(BeTrue())
+			Expect(namespaceDeletionProgressed(terminating, progressed)).To(BeTrue())
+			Expect(namespaceDeletionProgressed(progressed, relabelled)).To(BeFalse())
+			Expect(namespaceDeletionProgressed(namespace, namespace)).To(BeFalse())
+		})
+	})
+})
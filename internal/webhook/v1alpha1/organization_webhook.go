@@ -0,0 +1,356 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// DefaultReservedNames are the organization and namespace names denied by default.
+var DefaultReservedNames = []string{
+	"default",
+	"giantswarm",
+	"kube-node-lease",
+	"kube-public",
+	"kube-system",
+}
+
+// reservedKeys are the namespace labels and annotations set by the operator itself.
+var reservedKeys = []string{
+	securityv1alpha1.OrganizationLabel,
+	securityv1alpha1.ManagedByLabel,
+}
+
+const eventReasonDeletionBlocked = "DeletionBlocked"
+
+var organizationlog = logf.Log.WithName("organization-resource")
+
+// NamespaceNamer renders the namespace name of an organization.
+type NamespaceNamer interface {
+	NamespaceName(organization *securityv1alpha1.Organization) (string, error)
+}
+
+// SetupOrganizationWebhookWithManager registers the webhook for Organization in the manager.
+func SetupOrganizationWebhookWithManager(mgr ctrl.Manager, validator *OrganizationCustomValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&securityv1alpha1.Organization{}).
+		WithValidator(validator).
+		Complete()
+}
+
+//nolint:revive
+//+kubebuilder:webhook:path=/validate-security-giantswarm-io-v1alpha1-organization,mutating=false,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=security.giantswarm.io,resources=organizations,verbs=create;update;delete,versions=v1alpha1,name=vorganization-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// OrganizationCustomValidator validates Organization objects on create and
+// update, and enforces deletion protection on delete.
+type OrganizationCustomValidator struct {
+	// Client is used to look up namespaces colliding with new organizations.
+	Client client.Reader
+	// NamespaceNamer renders the namespace name of new organizations.
+	NamespaceNamer NamespaceNamer
+	// ReservedNames are the organization and namespace names that are denied.
+	ReservedNames []string
+	// Recorder records the blocked deletions on the organization.
+	Recorder record.EventRecorder
+	// MemberRoles are the member roles known to the operator. Member roles
+	// are not checked when empty.
+	MemberRoles []string
+}
+
+var _ webhook.CustomValidator = &OrganizationCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Organization.
+func (v *OrganizationCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	organization, ok := obj.(*securityv1alpha1.Organization)
+	if !ok {
+		return nil, fmt.Errorf("expected an Organization object but got %T", obj)
+	}
+	organizationlog.Info("Validation for Organization upon creation", "name", organization.GetName())
+
+	allErrs := v.validateName(organization)
+	if len(allErrs) == 0 {
+		errs, err := v.validateNamespace(ctx, organization)
+		if err != nil {
+			return nil, err
+		}
+		allErrs = append(allErrs, errs...)
+	}
+	allErrs = append(allErrs, validateSpec(organization, nil)...)
+
+	allErrs = append(allErrs, v.validateMembers(organization, nil)...)
+
+	return nil, invalid(organization, allErrs)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Organization.
+// Only the fields changed by the update are validated, so that organizations
+// which became invalid through a change of the rules or of the operator
+// configuration can still be updated, and deleted organizations are not
+// validated at all, so that their finalizers can always be removed.
+func (v *OrganizationCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	organization, ok := newObj.(*securityv1alpha1.Organization)
+	if !ok {
+		return nil, fmt.Errorf("expected an Organization object for the newObj but got %T", newObj)
+	}
+	oldOrganization, ok := oldObj.(*securityv1alpha1.Organization)
+	if !ok {
+		return nil, fmt.Errorf("expected an Organization object for the oldObj but got %T", oldObj)
+	}
+	organizationlog.Info("Validation for Organization upon update", "name", organization.GetName())
+
+	if organization.DeletionTimestamp != nil {
+		return nil, nil
+	}
+
+	allErrs := validateSpec(organization, oldOrganization)
+	allErrs = append(allErrs, v.validateMembers(organization, oldOrganization)...)
+
+	return nil, invalid(organization, allErrs)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Organization.
+// Blocked deletions are recorded as events, except for dry run requests.
+func (v *OrganizationCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	organization, ok := obj.(*securityv1alpha1.Organization)
+	if !ok {
+		return nil, fmt.Errorf("expected an Organization object but got %T", obj)
+	}
+	organizationlog.Info("Validation for Organization upon deletion", "name", organization.GetName())
+
+	if !organization.Spec.DeletionProtection {
+		return nil, nil
+	}
+	if organization.Annotations[securityv1alpha1.ConfirmDeletionAnnotation] == organization.Name {
+		return admission.Warnings{fmt.Sprintf("deleting protected organization %q confirmed by annotation", organization.Name)}, nil
+	}
+
+	if req, err := admission.RequestFromContext(ctx); err != nil || req.DryRun == nil || !*req.DryRun {
+		v.Recorder.Eventf(organization, corev1.EventTypeWarning, eventReasonDeletionBlocked,
+			"Deletion refused because deletion protection is enabled")
+	}
+	return nil, apierrors.NewForbidden(securityv1alpha1.GroupVersion.WithResource("organizations").GroupResource(), organization.Name,
+		fmt.Errorf("deletion protection is enabled, set spec.deletionProtection to false or annotate the organization with %s=%s to delete it",
+			securityv1alpha1.ConfirmDeletionAnnotation, organization.Name))
+}
+
+// validateName checks that the organization name can be used in labels and
+// renders a valid, non reserved namespace name.
+func (v *OrganizationCustomValidator) validateName(organization *securityv1alpha1.Organization) field.ErrorList {
+	var allErrs field.ErrorList
+	namePath := field.NewPath("metadata", "name")
+
+	for _, msg := range validation.IsDNS1123Label(organization.Name) {
+		allErrs = append(allErrs, field.Invalid(namePath, organization.Name, msg))
+	}
+	if slices.Contains(v.ReservedNames, organization.Name) {
+		allErrs = append(allErrs, field.Forbidden(namePath, fmt.Sprintf("organization name %q is reserved", organization.Name)))
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	namespaceName, err := v.namespaceName(organization)
+	if err != nil {
+		return append(allErrs, field.Invalid(namePath, organization.Name, err.Error()))
+	}
+	for _, msg := range validation.IsDNS1123Label(namespaceName) {
+		allErrs = append(allErrs, field.Invalid(namePath, organization.Name, fmt.Sprintf("namespace name %q: %s", namespaceName, msg)))
+	}
+	if slices.Contains(v.ReservedNames, namespaceName) {
+		allErrs = append(allErrs, field.Forbidden(namePath, fmt.Sprintf("namespace name %q is reserved", namespaceName)))
+	}
+
+	return allErrs
+}
+
+// validateNamespace checks that the namespace of a new organization does not
+// collide with an existing namespace that does not belong to it, unless the
+// organization opts in to adopt the namespace.
+func (v *OrganizationCustomValidator) validateNamespace(ctx context.Context, organization *securityv1alpha1.Organization) (field.ErrorList, error) {
+	namespaceName, err := v.namespaceName(organization)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := &corev1.Namespace{}
+	err = v.Client.Get(ctx, client.ObjectKey{Name: namespaceName}, namespace)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Namespace %s: %w", namespaceName, err)
+	}
+
+	if securityv1alpha1.IsLegacyNamespace(namespace, organization) || organization.Spec.AdoptNamespace {
+		return nil, nil
+	}
+	return field.ErrorList{
+		field.Forbidden(field.NewPath("metadata", "name"),
+			fmt.Sprintf("namespace %q already exists and does not belong to organization %q, set spec.adoptNamespace to adopt it",
+				namespaceName, organization.Name)),
+	}, nil
+}
+
+func (v *OrganizationCustomValidator) namespaceName(organization *securityv1alpha1.Organization) (string, error) {
+	if v.NamespaceNamer == nil {
+		return fmt.Sprintf("org-%s", organization.Name), nil
+	}
+	return v.NamespaceNamer.NamespaceName(organization)
+}
+
+// validateSpec checks the rules of the Organization spec that cannot be
+// expressed in the CRD schema. On updates, only the namespace metadata keys
+// and contacts changed against the old organization are checked.
+func validateSpec(organization, oldOrganization *securityv1alpha1.Organization) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if metadata := organization.Spec.NamespaceMetadata; metadata != nil {
+		var oldLabels, oldAnnotations map[string]string
+		if oldOrganization != nil && oldOrganization.Spec.NamespaceMetadata != nil {
+			oldLabels = oldOrganization.Spec.NamespaceMetadata.Labels
+			oldAnnotations = oldOrganization.Spec.NamespaceMetadata.Annotations
+		}
+		labels := changedKeys(metadata.Labels, oldLabels)
+		annotations := changedKeys(metadata.Annotations, oldAnnotations)
+
+		metadataPath := specPath.Child("namespaceMetadata")
+		allErrs = append(allErrs, metav1validation.ValidateLabels(labels, metadataPath.Child("labels"))...)
+		allErrs = append(allErrs, apivalidation.ValidateAnnotations(annotations, metadataPath.Child("annotations"))...)
+		allErrs = append(allErrs, validateReservedKeys(labels, metadataPath.Child("labels"))...)
+		allErrs = append(allErrs, validateReservedKeys(annotations, metadataPath.Child("annotations"))...)
+	}
+
+	if oldOrganization != nil && equality.Semantic.DeepEqual(organization.Spec.Contacts, oldOrganization.Spec.Contacts) {
+		return allErrs
+	}
+	emails := map[string]bool{}
+	for i, contact := range organization.Spec.Contacts {
+		email := strings.ToLower(contact.Email)
+		if emails[email] {
+			allErrs = append(allErrs, field.Duplicate(specPath.Child("contacts").Index(i).Child("email"), contact.Email))
+		}
+		emails[email] = true
+	}
+
+	return allErrs
+}
+
+// validateMembers checks that members are unique and that members and
+// automation accounts have a known role. On updates, members and automation
+// accounts that are unchanged from the old organization are not checked.
+func (v *OrganizationCustomValidator) validateMembers(organization, oldOrganization *securityv1alpha1.Organization) field.ErrorList {
+	var allErrs field.ErrorList
+	membersPath := field.NewPath("spec", "members")
+
+	var oldMembers []securityv1alpha1.OrganizationMember
+	var oldAccounts []securityv1alpha1.AutomationAccount
+	if oldOrganization != nil {
+		oldMembers = oldOrganization.Spec.Members
+		oldAccounts = oldOrganization.Spec.AutomationAccounts
+	}
+
+	members := map[securityv1alpha1.OrganizationMember]bool{}
+	for i, member := range organization.Spec.Members {
+		memberPath := membersPath.Index(i)
+		key := member
+		key.Role = ""
+		duplicate := members[key]
+		members[key] = true
+		if slices.Contains(oldMembers, member) {
+			continue
+		}
+
+		if member.Namespace != "" && member.Kind != securityv1alpha1.MemberKindServiceAccount {
+			allErrs = append(allErrs, field.Forbidden(memberPath.Child("namespace"), "namespace may only be set for service accounts"))
+		}
+		if len(v.MemberRoles) > 0 && !slices.Contains(v.MemberRoles, member.Role) {
+			allErrs = append(allErrs, field.NotSupported(memberPath.Child("role"), member.Role, v.MemberRoles))
+		}
+		if duplicate {
+			allErrs = append(allErrs, field.Duplicate(memberPath, member.Name))
+		}
+	}
+
+	if len(v.MemberRoles) > 0 {
+		accountsPath := field.NewPath("spec", "automationAccounts")
+		for i, account := range organization.Spec.AutomationAccounts {
+			if slices.ContainsFunc(oldAccounts, func(oldAccount securityv1alpha1.AutomationAccount) bool {
+				return equality.Semantic.DeepEqual(oldAccount, account)
+			}) {
+				continue
+			}
+			if !slices.Contains(v.MemberRoles, account.Role) {
+				allErrs = append(allErrs, field.NotSupported(accountsPath.Index(i).Child("role"), account.Role, v.MemberRoles))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// changedKeys returns the entries of metadata that are not set to the same
+// value in oldMetadata.
+func changedKeys(metadata, oldMetadata map[string]string) map[string]string {
+	changed := map[string]string{}
+	for key, value := range metadata {
+		if oldValue, ok := oldMetadata[key]; !ok || oldValue != value {
+			changed[key] = value
+		}
+	}
+	return changed
+}
+
+// validateReservedKeys rejects keys that are set by the operator itself.
+func validateReservedKeys(metadata map[string]string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for key := range metadata {
+		if slices.Contains(reservedKeys, key) || strings.HasPrefix(key, securityv1alpha1.ReservedKeyPrefix) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(key), "key is reserved to organization-operator"))
+		}
+		if strings.HasPrefix(key, securityv1alpha1.PodSecurityLabelPrefix) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(key), "key is managed through spec.podSecurity"))
+		}
+	}
+	return allErrs
+}
+
+func invalid(organization *securityv1alpha1.Organization, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(securityv1alpha1.GroupVersion.WithKind("Organization").GroupKind(), organization.Name, allErrs)
+}
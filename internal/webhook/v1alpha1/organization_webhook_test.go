@@ -0,0 +1,290 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Organization Webhook", func() {
+	var (
+		ctx       context.Context
+		recorder  *record.FakeRecorder
+		validator *OrganizationCustomValidator
+	)
+
+	newOrganization := func(name string) *securityv1alpha1.Organization {
+		return &securityv1alpha1.Organization{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = record.NewFakeRecorder(10)
+		validator = &OrganizationCustomValidator{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "org-taken"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+						Name:   "org-labelled",
+						Labels: map[string]string{"giantswarm.io/organization": "labelled"},
+					}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+						Name: "org-legacy",
+						Labels: map[string]string{
+							"giantswarm.io/organization": "legacy",
+							"giantswarm.io/managed-by":   "organization-operator",
+						},
+					}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+						Name: "org-released",
+						Labels: map[string]string{
+							"giantswarm.io/organization": "released",
+							"giantswarm.io/managed-by":   "organization-operator",
+						},
+						Annotations: map[string]string{"organization.giantswarm.io/released": "released"},
+					}},
+				).
+				Build(),
+			ReservedNames: DefaultReservedNames,
+			Recorder:      recorder,
+			MemberRoles:   []string{"admin", "editor", "viewer"},
+		}
+	})
+
+	Context("When creating an Organization", func() {
+		It("Should admit a valid organization", func() {
+			org := newOrganization("valid")
+			org.Spec.NamespaceMetadata = &securityv1alpha1.NamespaceMetadata{
+				Labels: map[string]string{"example.com/cost-center": "1234"},
+			}
+			_, err := validator.ValidateCreate(ctx, org)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny names resulting in a namespace name longer than 63 characters", func() {
+			_, err := validator.ValidateCreate(ctx, newOrganization(strings.Repeat("a", 60)))
+			Expect(err).To(MatchError(ContainSubstring("must be no more than 63 characters")))
+		})
+
+		It("Should deny names that are not DNS-1123 labels", func() {
+			_, err := validator.ValidateCreate(ctx, newOrganization("in.valid"))
+			Expect(err).To(MatchError(ContainSubstring("metadata.name")))
+		})
+
+		It("Should deny reserved names", func() {
+			_, err := validator.ValidateCreate(ctx, newOrganization("giantswarm"))
+			Expect(err).To(MatchError(ContainSubstring(`organization name "giantswarm" is reserved`)))
+		})
+
+		It("Should deny collisions with namespaces that do not belong to the organization", func() {
+			_, err := validator.ValidateCreate(ctx, newOrganization("taken"))
+			Expect(err).To(MatchError(ContainSubstring(`namespace "org-taken" already exists`)))
+		})
+
+		It("Should deny collisions with namespaces only carrying the organization label", func() {
+			_, err := validator.ValidateCreate(ctx, newOrganization("labelled"))
+			Expect(err).To(MatchError(ContainSubstring(`namespace "org-labelled" already exists`)))
+		})
+
+		It("Should deny collisions with namespaces released by another organization", func() {
+			_, err := validator.ValidateCreate(ctx, newOrganization("released"))
+			Expect(err).To(MatchError(ContainSubstring(`namespace "org-released" already exists`)))
+		})
+
+		It("Should admit namespaces created by a previous version of the operator", func() {
+			_, err := validator.ValidateCreate(ctx, newOrganization("legacy"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should admit collisions when the organization adopts the namespace", func() {
+			org := newOrganization("taken")
+			org.Spec.AdoptNamespace = true
+			_, err := validator.ValidateCreate(ctx, org)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny reserved and invalid namespace metadata", func() {
+			org := newOrganization("metadata")
+			org.Spec.NamespaceMetadata = &securityv1alpha1.NamespaceMetadata{
+				Labels: map[string]string{
+					"giantswarm.io/organization":         "other",
+					"example.com/invalid":                "not a label value",
+					"pod-security.kubernetes.io/enforce": "privileged",
+				},
+				Annotations: map[string]string{
+					"organization.giantswarm.io/display-name": "Other",
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, org)
+			Expect(err).To(MatchError(ContainSubstring("spec.namespaceMetadata.labels[giantswarm.io/organization]")))
+			Expect(err).To(MatchError(ContainSubstring("spec.namespaceMetadata.labels")))
+			Expect(err).To(MatchError(ContainSubstring("spec.namespaceMetadata.labels[pod-security.kubernetes.io/enforce]")))
+			Expect(err).To(MatchError(ContainSubstring("spec.namespaceMetadata.annotations[organization.giantswarm.io/display-name]")))
+		})
+
+		It("Should deny duplicate contacts", func() {
+			org := newOrganization("contacts")
+			org.Spec.Contacts = []securityv1alpha1.OrganizationContact{
+				{Name: "Jane Doe", Email: "jane@example.com"},
+				{Name: "Jane Doe", Email: "Jane@example.com"},
+			}
+			_, err := validator.ValidateCreate(ctx, org)
+			Expect(err).To(MatchError(ContainSubstring("spec.contacts[1].email")))
+		})
+
+		It("Should deny duplicate members and unknown roles", func() {
+			org := newOrganization("members")
+			org.Spec.Members = []securityv1alpha1.OrganizationMember{
+				{Kind: securityv1alpha1.MemberKindGroup, Name: "admins", Role: "admin"},
+				{Kind: securityv1alpha1.MemberKindGroup, Name: "admins", Role: "viewer"},
+				{Kind: securityv1alpha1.MemberKindUser, Name: "jane", Role: "owner"},
+				{Kind: securityv1alpha1.MemberKindUser, Name: "john", Namespace: "default", Role: "viewer"},
+			}
+			_, err := validator.ValidateCreate(ctx, org)
+			Expect(err).To(MatchError(ContainSubstring("spec.members[1]: Duplicate value")))
+			Expect(err).To(MatchError(ContainSubstring(`spec.members[2].role: Unsupported value: "owner"`)))
+			Expect(err).To(MatchError(ContainSubstring("spec.members[3].namespace")))
+		})
+
+		It("Should deny automation accounts with unknown roles", func() {
+			org := newOrganization("automation")
+			org.Spec.AutomationAccounts = []securityv1alpha1.AutomationAccount{
+				{Name: "ci", Role: "editor"},
+				{Name: "deploy", Role: "owner"},
+			}
+			_, err := validator.ValidateCreate(ctx, org)
+			Expect(err).To(MatchError(ContainSubstring(`spec.automationAccounts[1].role: Unsupported value: "owner"`)))
+		})
+	})
+
+	Context("When updating an Organization", func() {
+		It("Should validate the spec but not the existing namespace", func() {
+			oldOrg := newOrganization("taken")
+			newOrg := oldOrg.DeepCopy()
+			_, err := validator.ValidateUpdate(ctx, oldOrg, newOrg)
+			Expect(err).NotTo(HaveOccurred())
+
+			newOrg.Spec.NamespaceMetadata = &securityv1alpha1.NamespaceMetadata{
+				Labels: map[string]string{"giantswarm.io/managed-by": "someone"},
+			}
+			_, err = validator.ValidateUpdate(ctx, oldOrg, newOrg)
+			Expect(err).To(HaveOccurred())
+		})
+
+		Context("That became invalid after its creation", func() {
+			var invalidOrg *securityv1alpha1.Organization
+
+			BeforeEach(func() {
+				invalidOrg = newOrganization("invalid")
+				invalidOrg.Spec.NamespaceMetadata = &securityv1alpha1.NamespaceMetadata{
+					Labels: map[string]string{"pod-security.kubernetes.io/enforce": "privileged"},
+				}
+				invalidOrg.Spec.Members = []securityv1alpha1.OrganizationMember{
+					{Kind: securityv1alpha1.MemberKindGroup, Name: "owners", Role: "owner"},
+				}
+				invalidOrg.Finalizers = []string{"organization.giantswarm.io/finalizer"}
+			})
+
+			It("Should admit the removal of its finalizer once it is deleted", func() {
+				deletionTimestamp := metav1.Now()
+				invalidOrg.DeletionTimestamp = &deletionTimestamp
+				newOrg := invalidOrg.DeepCopy()
+				newOrg.Finalizers = nil
+				_, err := validator.ValidateUpdate(ctx, invalidOrg, newOrg)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("Should admit updates that do not touch the invalid fields", func() {
+				newOrg := invalidOrg.DeepCopy()
+				newOrg.Spec.DisplayName = "Invalid"
+				newOrg.Spec.NamespaceMetadata.Labels["example.com/cost-center"] = "1234"
+				_, err := validator.ValidateUpdate(ctx, invalidOrg, newOrg)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("Should deny new invalid members and metadata", func() {
+				newOrg := invalidOrg.DeepCopy()
+				newOrg.Spec.Members = append(newOrg.Spec.Members,
+					securityv1alpha1.OrganizationMember{Kind: securityv1alpha1.MemberKindUser, Name: "jane", Role: "owner"})
+				newOrg.Spec.NamespaceMetadata.Labels["pod-security.kubernetes.io/warn"] = "restricted"
+				_, err := validator.ValidateUpdate(ctx, invalidOrg, newOrg)
+				Expect(err).To(MatchError(ContainSubstring(`spec.members[1].role: Unsupported value: "owner"`)))
+				Expect(err).To(MatchError(ContainSubstring("spec.namespaceMetadata.labels[pod-security.kubernetes.io/warn]")))
+				Expect(err).NotTo(MatchError(ContainSubstring("spec.members[0]")))
+				Expect(err).NotTo(MatchError(ContainSubstring("pod-security.kubernetes.io/enforce")))
+			})
+		})
+	})
+
+	Context("When deleting an Organization", func() {
+		It("Should admit organizations without deletion protection", func() {
+			_, err := validator.ValidateDelete(ctx, newOrganization("unprotected"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("Should refuse protected organizations and record an Event", func() {
+			org := newOrganization("protected")
+			org.Spec.DeletionProtection = true
+			_, err := validator.ValidateDelete(ctx, org)
+			Expect(err).To(MatchError(ContainSubstring("deletion protection is enabled")))
+			Expect(recorder.Events).To(Receive(ContainSubstring("DeletionBlocked")))
+
+			By("Annotating the organization with a wrong confirm token")
+			org.Annotations = map[string]string{"organization.giantswarm.io/confirm-deletion": "other"}
+			_, err = validator.ValidateDelete(ctx, org)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should refuse protected organizations without recording an Event on dry run", func() {
+			org := newOrganization("protected")
+			org.Spec.DeletionProtection = true
+			dryRunCtx := admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{DryRun: ptr.To(true)},
+			})
+			_, err := validator.ValidateDelete(dryRunCtx, org)
+			Expect(err).To(MatchError(ContainSubstring("deletion protection is enabled")))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("Should admit protected organizations with the confirm token", func() {
+			org := newOrganization("confirmed")
+			org.Spec.DeletionProtection = true
+			org.Annotations = map[string]string{"organization.giantswarm.io/confirm-deletion": "confirmed"}
+			warnings, err := validator.ValidateDelete(ctx, org)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).NotTo(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,124 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+// SuspensionWebhookPath is the path the suspension webhook is served at.
+const SuspensionWebhookPath = "/validate-workloads"
+
+// controlPlaneGroup is the group of the service accounts the controllers of
+// the control plane run as.
+const controlPlaneGroup = "system:serviceaccounts:kube-system"
+
+// controlPlaneUser is the user the controllers of the control plane run as
+// when kube-controller-manager does not use service account credentials.
+const controlPlaneUser = "system:kube-controller-manager"
+
+// DefaultPlatformUsers are the users allowed to change workloads of suspended
+// organizations by default, covering the controllers of the control plane.
+var DefaultPlatformUsers = []string{
+	controlPlaneUser,
+}
+
+// DefaultPlatformGroups are the groups allowed to change workloads of
+// suspended organizations by default, covering cluster administrators and the
+// controllers of the control plane.
+var DefaultPlatformGroups = []string{
+	"system:masters",
+	"system:nodes",
+	controlPlaneGroup,
+}
+
+var suspensionlog = logf.Log.WithName("suspension")
+
+// SetupSuspensionWebhookWithManager registers the suspension webhook in the manager.
+func SetupSuspensionWebhookWithManager(mgr ctrl.Manager, handler *SuspensionHandler) error {
+	mgr.GetWebhookServer().Register(SuspensionWebhookPath, &webhook.Admission{Handler: handler})
+	return nil
+}
+
+//nolint:revive
+//+kubebuilder:webhook:path=/validate-workloads,mutating=false,failurePolicy=fail,sideEffects=None,groups="";apps;batch,resources=pods;replicationcontrollers;replicationcontrollers/scale;deployments;deployments/scale;statefulsets;statefulsets/scale;daemonsets;replicasets;replicasets/scale;jobs;cronjobs,verbs=create;update,versions=v1,name=vsuspension.kb.io,admissionReviewVersions=v1
+
+// SuspensionHandler denies creates and updates of workloads, including their
+// scale subresource, in the namespaces of suspended organizations, unless they
+// are made by platform identities. Pods are denied to the controllers of the
+// control plane too, so that they do not start new pods for the workloads of
+// suspended organizations.
+type SuspensionHandler struct {
+	// Client is used to look up the namespace of the workloads.
+	Client client.Reader
+	// PlatformUsers and PlatformGroups are the users and groups allowed to
+	// change workloads of suspended organizations.
+	PlatformUsers  []string
+	PlatformGroups []string
+}
+
+var _ admission.Handler = &SuspensionHandler{}
+
+// Handle implements admission.Handler.
+func (h *SuspensionHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Namespace == "" {
+		return admission.Allowed("")
+	}
+	if !createsControlPlanePod(req) && (slices.Contains(h.PlatformUsers, req.UserInfo.Username) ||
+		slices.ContainsFunc(req.UserInfo.Groups, func(group string) bool { return slices.Contains(h.PlatformGroups, group) })) {
+		return admission.Allowed("")
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := h.Client.Get(ctx, client.ObjectKey{Name: req.Namespace}, namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return admission.Allowed("")
+		}
+		return admission.Errored(http.StatusInternalServerError, fmt.Errorf("failed to get Namespace %s: %w", req.Namespace, err))
+	}
+	if namespace.Labels[securityv1alpha1.SuspendedLabel] != "true" {
+		return admission.Allowed("")
+	}
+
+	organization := namespace.Labels[securityv1alpha1.OrganizationLabel]
+	suspensionlog.Info("Denied workload change in suspended organization", "organization", organization,
+		"namespace", req.Namespace, "resource", req.Resource.Resource, "name", req.Name, "user", req.UserInfo.Username)
+	return admission.Denied(fmt.Sprintf("organization %q is suspended, %s cannot be created or updated in namespace %s",
+		organization, req.Resource.Resource, req.Namespace))
+}
+
+// createsControlPlanePod reports whether the request creates a pod on behalf
+// of a controller of the control plane, such as the Job or ReplicaSet
+// controller.
+func createsControlPlanePod(req admission.Request) bool {
+	return req.Operation == admissionv1.Create && req.Resource.Group == "" && req.Resource.Resource == "pods" &&
+		req.SubResource == "" && (req.UserInfo.Username == controlPlaneUser || slices.Contains(req.UserInfo.Groups, controlPlaneGroup))
+}
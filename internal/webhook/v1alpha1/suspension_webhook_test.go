@@ -0,0 +1,136 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+)
+
+var _ = Describe("Suspension Webhook", func() {
+	var (
+		ctx     context.Context
+		handler *SuspensionHandler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		handler = &SuspensionHandler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithObjects(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+						Name: "org-suspended",
+						Labels: map[string]string{
+							securityv1alpha1.OrganizationLabel: "suspended",
+							securityv1alpha1.SuspendedLabel:    "true",
+						},
+					}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+						Name:   "org-active",
+						Labels: map[string]string{securityv1alpha1.OrganizationLabel: "active"},
+					}},
+				).
+				Build(),
+			PlatformUsers:  append([]string{"system:serviceaccount:giantswarm:organization-operator"}, DefaultPlatformUsers...),
+			PlatformGroups: DefaultPlatformGroups,
+		}
+	})
+
+	request := func(namespace, username string, groups ...string) admission.Request {
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Namespace: namespace,
+			Name:      "web",
+			Resource:  metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			UserInfo:  authenticationv1.UserInfo{Username: username, Groups: groups},
+		}}
+	}
+
+	scaleRequest := func(namespace, username string, groups ...string) admission.Request {
+		req := request(namespace, username, groups...)
+		req.SubResource = "scale"
+		return req
+	}
+
+	podRequest := func(namespace, username string, groups ...string) admission.Request {
+		req := request(namespace, username, groups...)
+		req.Operation = admissionv1.Create
+		req.Resource = metav1.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return req
+	}
+
+	It("Should deny workload changes in suspended organizations", func() {
+		response := handler.Handle(ctx, request("org-suspended", "jane@example.com", "customer-admins"))
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Message).To(ContainSubstring(`organization "suspended" is suspended`))
+	})
+
+	It("Should deny scaling workloads in suspended organizations", func() {
+		response := handler.Handle(ctx, scaleRequest("org-suspended", "jane@example.com", "customer-admins"))
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Message).To(ContainSubstring(`organization "suspended" is suspended`))
+	})
+
+	It("Should admit workload changes of platform identities", func() {
+		Expect(handler.Handle(ctx, request("org-suspended", "system:serviceaccount:giantswarm:organization-operator")).Allowed).To(BeTrue())
+		Expect(handler.Handle(ctx, request("org-suspended", "system:serviceaccount:kube-system:deployment-controller",
+			"system:serviceaccounts", "system:serviceaccounts:kube-system")).Allowed).To(BeTrue())
+		Expect(handler.Handle(ctx, scaleRequest("org-suspended", "admin", "system:masters")).Allowed).To(BeTrue())
+		Expect(handler.Handle(ctx, podRequest("org-suspended", "admin", "system:masters")).Allowed).To(BeTrue())
+	})
+
+	It("Should deny pods created by the controllers of the control plane", func() {
+		for _, controller := range []string{"job-controller", "replicaset-controller", "daemon-set-controller"} {
+			response := handler.Handle(ctx, podRequest("org-suspended", "system:serviceaccount:kube-system:"+controller,
+				"system:serviceaccounts", "system:serviceaccounts:kube-system"))
+			Expect(response.Allowed).To(BeFalse(), controller)
+		}
+	})
+
+	It("Should admit workload changes of kube-controller-manager without service account credentials", func() {
+		req := scaleRequest("org-suspended", "system:kube-controller-manager", "system:authenticated")
+		req.Resource = metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}
+		Expect(handler.Handle(ctx, req).Allowed).To(BeTrue())
+		Expect(handler.Handle(ctx, request("org-suspended", "system:kube-controller-manager", "system:authenticated")).Allowed).To(BeTrue())
+	})
+
+	It("Should deny pods created by kube-controller-manager without service account credentials", func() {
+		response := handler.Handle(ctx, podRequest("org-suspended", "system:kube-controller-manager", "system:authenticated"))
+		Expect(response.Allowed).To(BeFalse())
+	})
+
+	It("Should admit pods created by the controllers of the control plane in other namespaces", func() {
+		Expect(handler.Handle(ctx, podRequest("org-active", "system:serviceaccount:kube-system:job-controller",
+			"system:serviceaccounts", "system:serviceaccounts:kube-system")).Allowed).To(BeTrue())
+	})
+
+	It("Should admit workload changes in other namespaces", func() {
+		Expect(handler.Handle(ctx, request("org-active", "jane@example.com")).Allowed).To(BeTrue())
+		Expect(handler.Handle(ctx, request("unknown", "jane@example.com")).Allowed).To(BeTrue())
+	})
+})
@@ -20,15 +20,22 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -37,6 +44,7 @@ import (
 
 	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
 	"github.com/giantswarm/organization-operator/internal/controller"
+	webhookv1alpha1 "github.com/giantswarm/organization-operator/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -58,6 +66,22 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var namespaceNameTemplate string
+	var enableWebhooks bool
+	var reservedNames string
+	var memberRoles string
+	var networkIsolation bool
+	var platformNamespaces string
+	var podSecurityDefaults string
+	var classRolloutInterval time.Duration
+	var automationTokenExpiration time.Duration
+	var kubeconfigServer string
+	var platformUsers string
+	var platformGroups string
+	var replicationSourceNamespaces string
+	var deletionStuckThreshold time.Duration
+	var deletionTimeout time.Duration
+	var resyncPeriod time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8000", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -67,6 +91,42 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&namespaceNameTemplate, "namespace-name-template", controller.DefaultNamespaceNameTemplate,
+		"The text/template rendering the namespace name of new organizations. "+
+			"Namespaces of existing organizations are never renamed.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", true,
+		"If set, the validating webhook for organizations is served.")
+	flag.StringVar(&reservedNames, "reserved-names", strings.Join(webhookv1alpha1.DefaultReservedNames, ","),
+		"Comma separated list of organization and namespace names the webhook denies.")
+	flag.StringVar(&memberRoles, "member-roles", controller.FormatMemberRoles(controller.DefaultMemberRoles),
+		"Comma separated list of role=ClusterRole pairs mapping the organization member roles to the ClusterRoles bound for them.")
+	flag.BoolVar(&networkIsolation, "network-isolation", false,
+		"If set, organization namespaces are isolated with NetworkPolicies unless the organization disables it.")
+	flag.StringVar(&platformNamespaces, "platform-namespaces", "kube-system",
+		"Comma separated list of namespaces that may reach isolated organization namespaces.")
+	flag.StringVar(&podSecurityDefaults, "pod-security-defaults", "",
+		"Comma separated list of key=value pairs with keys enforce, enforce-version, audit, audit-version, warn and warn-version, "+
+			"setting the Pod Security Admission levels of organizations that do not set them.")
+	flag.DurationVar(&classRolloutInterval, "class-rollout-interval", controller.DefaultClassRolloutInterval,
+		"The interval between the organizations reconciled when an OrganizationClass changes.")
+	flag.DurationVar(&automationTokenExpiration, "automation-token-expiration", controller.DefaultAutomationTokenExpiration,
+		"The lifetime of the tokens issued to automation accounts that do not set it.")
+	flag.StringVar(&kubeconfigServer, "kubeconfig-server", "",
+		"The API server address written into the kubeconfigs of automation accounts, as reachable by their users. "+
+			"Automation accounts are not reconciled without it.")
+	flag.StringVar(&platformUsers, "platform-users", strings.Join(webhookv1alpha1.DefaultPlatformUsers, ","),
+		"Comma separated list of users allowed to change workloads of suspended organizations.")
+	flag.StringVar(&platformGroups, "platform-groups", strings.Join(webhookv1alpha1.DefaultPlatformGroups, ","),
+		"Comma separated list of groups allowed to change workloads of suspended organizations.")
+	flag.StringVar(&replicationSourceNamespaces, "replication-source-namespaces", "",
+		"Comma separated list of namespaces whose annotated Secrets and ConfigMaps are replicated into organization namespaces.")
+	flag.DurationVar(&deletionStuckThreshold, "deletion-stuck-threshold", controller.DefaultDeletionStuckThreshold,
+		"The time after which an organization deletion that has not completed is reported as stuck.")
+	flag.DurationVar(&deletionTimeout, "deletion-timeout", controller.DefaultDeletionTimeout,
+		"The time after which the namespace of a deleted organization is no longer polled and the organization reports DeletionStalled.")
+	flag.DurationVar(&resyncPeriod, "resync-period", controller.DefaultResyncPeriod,
+		"The interval at which every organization is reconciled again, verifying its managed objects without any watch event. "+
+			"The resync period of the cache is left at its default.")
 	opts := zap.Options{
 		Development: false,
 	}
@@ -75,6 +135,24 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	namespaceNamer, err := controller.NewNamespaceNameTemplate(namespaceNameTemplate)
+	if err != nil {
+		setupLog.Error(err, "invalid namespace name template")
+		os.Exit(1)
+	}
+
+	memberRoleMapping, err := controller.ParseMemberRoles(memberRoles)
+	if err != nil {
+		setupLog.Error(err, "invalid member roles")
+		os.Exit(1)
+	}
+
+	podSecurity, err := controller.ParsePodSecurity(podSecurityDefaults)
+	if err != nil {
+		setupLog.Error(err, "invalid pod security defaults")
+		os.Exit(1)
+	}
+
 	disableHTTP2 := func(c *tls.Config) {
 		setupLog.Info("disabling http/2")
 		c.NextProtos = []string{"http/1.1"}
@@ -89,7 +167,14 @@ func main() {
 		TLSOpts: tlsOpts,
 	})
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	restConfig := ctrl.GetConfigOrDie()
+	kubeconfigCA, err := certificateAuthority(restConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to read the API server CA")
+		os.Exit(1)
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress:    metricsAddr,
@@ -97,6 +182,9 @@ func main() {
 			TLSOpts:        tlsOpts,
 			FilterProvider: filters.WithAuthenticationAndAuthorization,
 		},
+		Cache: cache.Options{
+			ByObject: controller.ReplicationCacheOptions(splitList(replicationSourceNamespaces)),
+		},
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
@@ -108,12 +196,82 @@ func main() {
 	}
 
 	if err = (&controller.OrganizationReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("organization-controller"),
+
+		NamespaceNameTemplate: namespaceNamer,
+		APIReader:             mgr.GetAPIReader(),
+		MemberRoles:           memberRoleMapping,
+		NetworkIsolation:      networkIsolation,
+		PlatformNamespaces:    splitList(platformNamespaces),
+		PodSecurityDefaults:   podSecurity,
+		PodSecurityDryRunner:  controller.NewPodSecurityDryRunner(mgr.GetConfig()),
+		ClassRolloutInterval:  classRolloutInterval,
+
+		AutomationTokenExpiration: automationTokenExpiration,
+		KubeconfigServer:          kubeconfigServer,
+		KubeconfigCA:              kubeconfigCA,
+		DeletionStuckThreshold:    deletionStuckThreshold,
+		DeletionTimeout:           deletionTimeout,
+		ResyncPeriod:              resyncPeriod,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Organization")
+		os.Exit(1)
+	}
+	if err = mgr.Add(&controller.FinalizerMigration{
+		Client:   mgr.GetClient(),
+		Recorder: mgr.GetEventRecorderFor("organization-controller"),
+	}); err != nil {
+		setupLog.Error(err, "unable to set up finalizer migration")
+		os.Exit(1)
+	}
+	if err = (&controller.OrganizationTemplateReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Organization")
+		setupLog.Error(err, "unable to create controller", "controller", "OrganizationTemplate")
 		os.Exit(1)
 	}
+	for _, object := range []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}} {
+		if err = (&controller.ReplicationReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("organization-replication-controller"),
+			Object:   object,
+
+			SourceNamespaces: splitList(replicationSourceNamespaces),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Replication")
+			os.Exit(1)
+		}
+	}
+	if enableWebhooks {
+		memberRoleNames := make([]string, 0, len(memberRoleMapping))
+		for role := range memberRoleMapping {
+			memberRoleNames = append(memberRoleNames, role)
+		}
+		slices.Sort(memberRoleNames)
+
+		if err = webhookv1alpha1.SetupOrganizationWebhookWithManager(mgr, &webhookv1alpha1.OrganizationCustomValidator{
+			Client:         mgr.GetClient(),
+			NamespaceNamer: namespaceNamer,
+			ReservedNames:  splitList(reservedNames),
+			Recorder:       mgr.GetEventRecorderFor("organization-webhook"),
+			MemberRoles:    memberRoleNames,
+		}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Organization")
+			os.Exit(1)
+		}
+		if err = webhookv1alpha1.SetupSuspensionWebhookWithManager(mgr, &webhookv1alpha1.SuspensionHandler{
+			Client:         mgr.GetClient(),
+			PlatformUsers:  splitList(platformUsers),
+			PlatformGroups: splitList(platformGroups),
+		}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Suspension")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -131,3 +289,23 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList splits a comma separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// certificateAuthority returns the CA bundle of the API server the operator
+// connects to.
+func certificateAuthority(config *rest.Config) ([]byte, error) {
+	if len(config.CAData) > 0 || config.CAFile == "" {
+		return config.CAData, nil
+	}
+	return os.ReadFile(config.CAFile)
+}